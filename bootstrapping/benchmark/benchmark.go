@@ -2,12 +2,14 @@ package main
 
 import (
 	"encoding/csv"
+	"errors"
 	"flag"
 	"log"
 	"os"
 	"runtime"
 	bs "sabot/bootstrapping"
 	"sabot/lib/database"
+	"sabot/lib/util"
 	"time"
 )
 
@@ -21,6 +23,8 @@ const (
 var (
 	pathRead  = flag.String("path", defaultPath, "path for reading benchmark configs.")
 	pathWrite = flag.String("out", defaultOut, "path for writing benchmark results.")
+	// the servers only accept round flips authenticated with this key
+	roundKeyPath = flag.String("roundkey", "", "file with the hex key the servers got with -roundkey, needed for epochs")
 )
 
 /*
@@ -33,6 +37,13 @@ func main() {
 	flag.Parse()
 
 	rConfig := bs.ReadBenchConfigs(*pathRead)
+	var roundKey []byte
+	if *roundKeyPath != "" {
+		var err error
+		if roundKey, err = util.ReadKeyFile(*roundKeyPath); err != nil {
+			log.Fatal("could not read -roundkey: ", err)
+		}
+	}
 	file, err := os.Create(*pathWrite)
 	if err != nil {
 		log.Fatal("error creating file", err)
@@ -49,6 +60,12 @@ func main() {
 
 		// SETUP:  Init Client and Server
 		c := bs.InitClient(&config, &bs.ServerInfo{Addr: []string{rConfig.Addr1, rConfig.Addr2}})
+		// with epochs, notifications become readable after the round flips
+		stop := make(chan struct{})
+		if c.Schedule().Length > 0 && roundKey == nil {
+			log.Fatal("epochs need the flag -roundkey")
+		}
+		go bs.RunRounds(c.GrpcClients, roundKey, c.Schedule(), stop)
 		// For Benchmarking: Get random keywords (that are included in the database)
 		// and the client's kw from server
 		recvKWs := make([][]byte, c.RateS)
@@ -59,33 +76,45 @@ func main() {
 			//	Sender Retrieval
 			// Run KW PIR to get contact info of receivers
 			start = time.Now()
-			receivers := c.GetReceiverInfo(recvKWs)
+			receivers, err := c.GetReceiverInfo(recvKWs)
+			if err != nil {
+				log.Fatal("could not retrieve the receivers: ", err)
+			}
 			c.RT["SendPIR"] += time.Since(start)
 
 			//	Sender Notification
 			start = time.Now()
-			c.Notify(receivers, true)
+			if err := c.Notify(receivers, true); err != nil {
+				log.Fatal("could not notify the receivers: ", err)
+			}
 			c.RT["SendNotify"] += time.Since(start)
 
 			// Receiver GetNotificaion
 			start = time.Now()
-			senderIndices := c.GetNotified(false)
+			senderIndices, err := c.GetNotified(false)
+			checkNotified(err)
 			c.RT["RecvGetNotified"] += time.Since(start)
 
 			// Receiver Retrieval
 			start = time.Now()
-			senders := c.GetSenders(senderIndices)
+			senders, err := c.GetSenders(senderIndices)
+			if err != nil {
+				log.Fatal("could not retrieve the senders: ", err)
+			}
 			c.RT["RecvPIR"] += time.Since(start)
 
 			// Receiver Notification
 			start = time.Now()
-			c.Notify(senders, false)
+			if err := c.Notify(senders, false); err != nil {
+				log.Fatal("could not notify the senders: ", err)
+			}
 			c.RT["RecvNotify"] += time.Since(start)
 
 			// Sender getNotified
 			start = time.Now()
 			// client should match based on this info with whom they have now exchanged infos
-			c.GetNotified(true)
+			_, err = c.GetNotified(true)
+			checkNotified(err)
 			c.RT["SendGetNotified"] += time.Since(start)
 
 			runtime.GC()
@@ -98,6 +127,7 @@ func main() {
 			// Reset exp, BW and RT summary
 			c.ResetBenchVars()
 		}
+		close(stop)
 		for _, conn := range c.ServerInfo.Conns {
 			conn.Close()
 		}
@@ -106,3 +136,13 @@ func main() {
 	}
 	file.Close()
 }
+
+// the benchmark goes on with the authentic senders if notifications failed their MACs
+func checkNotified(err error) {
+	var macErr *bs.MacError
+	if errors.As(err, &macErr) {
+		log.Println(err)
+	} else if err != nil {
+		log.Fatal("could not get notified: ", err)
+	}
+}
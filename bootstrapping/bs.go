@@ -5,21 +5,40 @@ import (
 	"log"
 	"os"
 	"sabot/lib/database"
+	"sabot/lib/notify"
 	"strconv"
 	"time"
 )
 
 // Experiment Config
 type Config struct {
-	Idx         uint32
-	Dbfile      string
-	RateR       uint32
-	RateS       uint32
-	MultiClient bool
-	NumThreads  uint32
-	ResetServer bool
-	Repetitions uint32
-	DBType      uint32 // 0: 2 DBs
+	Idx          uint32
+	Dbfile       string
+	RateR        uint32
+	RateS        uint32
+	MultiClient  bool
+	NumThreads   uint32
+	ResetServer  bool
+	Repetitions  uint32
+	DBType       uint32 // 0: 2 DBs
+	SingleServer bool   // retrieval with single-server LWE-PIR instead of two-server DPF-PIR
+	PayloadLen   uint32 // bytes of payload delivered with each notification, 0 for bit-only notifications
+	// notification epochs, both servers expire notifications after EpochRetention epochs
+	EpochLength    uint64 // seconds, 0 disables epochs
+	EpochStart     int64  // unix time of the begin of epoch 0
+	EpochRetention uint32
+	VerifyColumns  bool // servers check together that a notification has at most RateS targets
+	Reshare        bool // servers re-randomize the stored notifications with every round flip, needs epochs
+	DPFNotify      bool // senders upload a DPF key per target instead of a share of the whole column
+}
+
+// returns the epoch schedule of the notifications
+func (conf *Config) Schedule() notify.Schedule {
+	return notify.Schedule{
+		Start:     time.Unix(conf.EpochStart, 0),
+		Length:    time.Duration(conf.EpochLength) * time.Second,
+		Retention: uint64(conf.EpochRetention),
+	}
 }
 
 // Experiment Suite
@@ -45,6 +64,8 @@ func NewExperiment(config *Config) *Experiment {
 }
 
 func (exp *Experiment) ResetBenchVars() {
+	// the LWE hint is downloaded once during setup and reported with every repetition
+	hintBW := exp.BW["PIRHintDown"]
 	exp.BW = map[string]uint32{
 		"SendNotifyUp":        0,
 		"SendNotifyDown":      0,
@@ -58,6 +79,7 @@ func (exp *Experiment) ResetBenchVars() {
 		"SendPIRDown":         0,
 		"RecvPIRUp":           0,
 		"RecvPIRDown":         0,
+		"PIRHintDown":         hintBW,
 	}
 	exp.RT = map[string]time.Duration{
 		"SendNotify":      0,
@@ -96,6 +118,12 @@ func GetOutputString(exp *Experiment, pp database.DBParams, i int) []string {
 		} else if key == "multi_client" {
 			log.Println("multi_client:", strconv.FormatBool(exp.MultiClient))
 			out = append(out, strconv.FormatBool(exp.MultiClient))
+		} else if key == "single_server" {
+			log.Println("single_server:", strconv.FormatBool(exp.SingleServer))
+			out = append(out, strconv.FormatBool(exp.SingleServer))
+		} else if key == "payload_length" {
+			log.Println("payload_length:", strconv.Itoa(int(exp.PayloadLen)))
+			out = append(out, strconv.Itoa(int(exp.PayloadLen)))
 		} else if key == "num_threads" {
 			log.Println("num_threads:", strconv.Itoa(int(exp.NumThreads)))
 			out = append(out, strconv.Itoa(int(exp.NumThreads)))
@@ -141,6 +169,8 @@ var Headers = []string{
 	"rate",
 	"multi_client",
 	"num_threads",
+	"single_server",
+	"payload_length",
 	"repetition",
 	"BW_SendPIRUp",
 	"BW_SendPIRDown",
@@ -154,6 +184,7 @@ var Headers = []string{
 	"BW_RecvNotifyDown",
 	"BW_SendGetNotifiedUp",
 	"BW_SendGetNotifiedDown",
+	"BW_PIRHintDown",
 	"RT_SendPIR",
 	"RT_SendNotify",
 	"RT_RecvGetNotified",
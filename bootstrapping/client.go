@@ -3,12 +3,16 @@ package bootstrapping
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"sabot/lib/database"
 	"sabot/lib/notify"
 	"sabot/lib/pir"
 	"sabot/lib/util"
 	pb "sabot/proto/bootstrapping"
+	"slices"
 	"sync"
 	"time"
 
@@ -32,9 +36,15 @@ type Client struct {
 	Id        []byte // client's identifier in DB
 	Pps       []*database.DBParams
 	Dpfs      []*pir.DpfClient
-	NumServer int // number of servers (= 2)
-	DpfSeed   int64
+	Lwes      []*pir.LWEClient // only used for single-server retrieval
+	NumServer int              // number of servers (= 2)
 	Contacts  *[]database.IKVElement
+	Mailbox   notify.Mailbox // notifications already read with GetNewNotifications
+	Epoch     uint64         // epoch the servers write to, as far as the client knows
+	// keywords and senders that did not fit into the fixed number of queries of a call,
+	// they are retrieved first by the following calls (see Backlog)
+	recvQueue   [][]byte
+	senderQueue []uint32
 	*ServerInfo
 }
 
@@ -42,7 +52,6 @@ func InitClient(config *Config, sInfo *ServerInfo) *Client {
 	c := Client{}
 	c.Experiment = NewExperiment(config)
 	c.NumServer = len(sInfo.Addr)
-	c.DpfSeed = util.DPF_SEED
 
 	// Set up  server infos and connections
 	c.ServerInfo = sInfo
@@ -72,6 +81,15 @@ func InitClient(config *Config, sInfo *ServerInfo) *Client {
 		c.Dpfs[i] = pir.InitPIRClient(&database.StaticDBParams{NRows: int(pp.NRows)}, pir.RandSource())
 	}
 
+	if c.SingleServer {
+		c.Lwes = make([]*pir.LWEClient, len(c.Pps))
+		for i := range c.Pps {
+			if c.Lwes[i], err = c.getLWEHint(database.QueryType(i)); err != nil {
+				log.Fatalln("could not get LWE hint:", err)
+			}
+		}
+	}
+
 	return &c
 }
 
@@ -92,13 +110,22 @@ func initWorker(c *Client, wg *sync.WaitGroup, i int, creds *credentials.Transpo
 	ctx, cancel = context.WithDeadline(ctx, clientDeadline)
 	defer cancel()
 	conf := &pb.Config{
-		ResetServer: c.Config.ResetServer,
-		Dbfile:      c.Config.Dbfile,
-		MultiClient: c.Config.MultiClient,
-		NumThreads:  c.Config.NumThreads,
-		CIdx:        c.Idx,
-		NumTargets:  c.RateS,
-		DbType:      util.Uint32ToByteSlice(uint32(c.Config.DBType)),
+		ResetServer:  c.Config.ResetServer,
+		Dbfile:       c.Config.Dbfile,
+		MultiClient:  c.Config.MultiClient,
+		NumThreads:   c.Config.NumThreads,
+		CIdx:         c.Idx,
+		NumTargets:   c.RateS,
+		ServerID:     uint32(i),
+		DbType:       util.Uint32ToByteSlice(uint32(c.Config.DBType)),
+		SingleServer: c.Config.SingleServer,
+		PayloadLen:   c.Config.PayloadLen,
+		// both servers must be set up with the same schedule
+		EpochLength:    c.Config.EpochLength,
+		EpochStart:     c.Config.EpochStart,
+		EpochRetention: c.Config.EpochRetention,
+		VerifyColumns:  c.Config.VerifyColumns,
+		Reshare:        c.Config.Reshare,
 	}
 
 	res, err := (*c.GrpcClients[i]).SetupExperiment(ctx, conf)
@@ -137,52 +164,92 @@ func initWorker(c *Client, wg *sync.WaitGroup, i int, creds *credentials.Transpo
 	}
 }
 
-func (c *Client) GetReceiverInfo(recvKW [][]byte) *[]database.IKVElement {
+// Downloads LWE parameters and hint for a database from the first server.
+// The hint can be larger than the maximum message size, so it is fetched in chunks of rows.
+func (c *Client) getLWEHint(queryType database.QueryType) (*pir.LWEClient, error) {
+	clientDeadline := time.Now().Add(util.TIMEOUT)
+	ctx, cancel := context.WithTimeout(context.Background(), util.TIMEOUT)
+	ctx, cancel = context.WithDeadline(ctx, clientDeadline)
+	defer cancel()
 
-	queriesGRPC := make([][]*pb.Query, c.NumServer)
-	for i := 0; i < c.NumServer; i++ {
-		queriesGRPC[i] = make([]*pb.Query, c.RateS*util.ARITY)
+	var params *pir.LWEParams
+	var hint []uint32
+	var from, to uint32
+	for params == nil || int(from) < params.M {
+		pb_in := &pb.HintRequest{QueryType: util.Uint32ToByteSlice(uint32(queryType)), From: from, To: to}
+		res, err := (*c.GrpcClients[0]).GetLWEHint(ctx, pb_in)
+		if err != nil {
+			return nil, fmt.Errorf("could not get hint: %w", err)
+		}
+		c.BW["PIRHintDown"] += uint32(proto.Size(res))
+		if params == nil {
+			if res.Params == nil || res.Params.N == 0 || res.Params.M == 0 || res.Params.RowLen == 0 || res.Params.NumCols == 0 {
+				return nil, errors.New("server sent invalid LWE parameters")
+			}
+			params = &pir.LWEParams{
+				N:          res.Params.N,
+				NumRecords: int(res.Params.NumRecords),
+				RowLen:     int(res.Params.RowLen),
+				NumCols:    int(res.Params.NumCols),
+				M:          int(res.Params.M),
+			}
+			copy(params.Seed[:], res.Params.Seed)
+			hint = make([]uint32, 0, params.M*int(params.N))
+		}
+		// the rows of the hint have N entries each
+		if len(res.Hint) != 4*int(to-from)*int(params.N) {
+			return nil, fmt.Errorf("hint rows %d to %d have length %d, expected %d", from, to, len(res.Hint), 4*int(to-from)*int(params.N))
+		}
+		rows, err := util.BytesToUint32Slice(res.Hint)
+		if err != nil {
+			return nil, err
+		}
+		hint = append(hint, rows...)
+		// first request only returns the parameters
+		from = to
+		to = from + uint32(util.MAX_MSG_SIZE/2/(4*int(params.N)))
+		if int(to) > params.M {
+			to = uint32(params.M)
+		}
 	}
+	return pir.InitLWEClient(params, hint), nil
+}
+
+/*
+Runs KW-PIR for the records of the receivers with the given keywords.
+The client always makes RateS queries: keywords that do not fit are queued and retrieved
+(before new keywords) by the following calls, so only the records of the first RateS
+queued keywords are returned. Free queries are filled with dummies.
+*/
+func (c *Client) GetReceiverInfo(recvKW [][]byte) (*[]database.IKVElement, error) {
+	recvKW = takeQueued(&c.recvQueue, recvKW, int(c.RateS), bytes.Equal)
+
 	// Keep list of keywords and their according indices to find desired record
 	// (and ignore dummy requests in non-auth case)
 	queryKws := make([][]byte, c.RateS)
 
 	// Client has to make fixed number of requests (rates*arity many),
 	// if len(recvKW) < c.Rate S: generate dummy queries based on own idx
-	var indices []uint32
+	indices := make([]uint32, 0, c.RateS*util.ARITY)
 	for i := 0; i < int(c.RateS); i++ {
 		// add real queries
 		if i < len(recvKW) {
-			indices = c.Pps[database.Kw].GetIndices(recvKW[i])
-			for j, idx := range indices {
-				dpfKeys, _ := c.Dpfs[database.Kw].Query(int(idx))
-				for k := 0; k < c.NumServer; k++ {
-					queriesGRPC[k][i*util.ARITY+j] = &pb.Query{DpfKey: *dpfKeys[k]}
-				}
-			}
+			indices = append(indices, c.Pps[database.Kw].GetIndices(recvKW[i])...)
 			queryKws[i] = recvKW[i]
 		} else { // add dummy keywords and their indices
 			for j := 0; j < util.ARITY; j++ {
-				dpfKeys, _ := c.Dpfs[database.Kw].Query(int(c.Idx))
-				for k := 0; k < c.NumServer; k++ {
-					queriesGRPC[k][i*util.ARITY+j] = &pb.Query{DpfKey: *dpfKeys[k]}
-				}
+				indices = append(indices, c.Idx)
 			}
 			queryKws[i] = c.Id
 		}
 	}
 
-	// Send all queries in parallel to servers
-	ans_grpc := make([]*pb.Answers, c.NumServer)
-
-	var wg sync.WaitGroup
-	wg.Add(c.NumServer)
-	for i := 0; i < int(c.NumServer); i++ {
-		go makeQueriesWorker(c, &wg, i, &queriesGRPC, &ans_grpc, true)
+	rows, err := c.retrieve(database.Kw, indices, true)
+	if err != nil {
+		return nil, err
 	}
-	wg.Wait()
 
-	// Reconstruct DB records from answers
+	// Find DB records in retrieved rows
 	var contactData []database.IKVElement
 	for i, kw := range queryKws {
 
@@ -191,17 +258,12 @@ func (c *Client) GetReceiverInfo(recvKW [][]byte) *[]database.IKVElement {
 			continue
 		}
 		for j := 0; j < util.ARITY; j++ {
-			out, err := c.Dpfs[database.Kw].Reconstruct(
-				[][]byte{ans_grpc[0].Answers[i*util.ARITY+j].Answer,
-					ans_grpc[1].Answers[i*util.ARITY+j].Answer})
-			if err != nil {
-				log.Fatalf("failed to reconstruct answer")
-			}
+			out := rows[i*util.ARITY+j]
 			if c.Pps[database.Kw].Auth {
 				// Verify proof and remove proof from out
 				out, err = c.Pps[database.Kw].VerifyRow(out)
 				if err != nil {
-					log.Fatalf("reject received pir answers, proof rejected")
+					return nil, fmt.Errorf("reject received pir answers, proof rejected: %v", err)
 				}
 			}
 			if bytes.Equal(out[:c.Pps[database.Kw].KeyLength], kw) {
@@ -211,11 +273,134 @@ func (c *Client) GetReceiverInfo(recvKW [][]byte) *[]database.IKVElement {
 			}
 		}
 	}
-	return &contactData
+	return &contactData, nil
+
+}
+
+// Backlog returns the number of receiver keywords and of senders that are queued for the next calls of GetReceiverInfo and GetSenders
+func (c *Client) Backlog() (int, int) {
+	return len(c.recvQueue), len(c.senderQueue)
+}
+
+// appends the items that are not queued yet to the queue, removes the first (at most) n items from the queue and returns them
+func takeQueued[T any](queue *[]T, items []T, n int, equal func(T, T) bool) []T {
+	for _, item := range items {
+		if !slices.ContainsFunc(*queue, func(q T) bool { return equal(q, item) }) {
+			*queue = append(*queue, item)
+		}
+	}
+	n = min(n, len(*queue))
+	out := slices.Clone((*queue)[:n])
+	*queue = slices.Delete(*queue, 0, n)
+	return out
+}
+
+// Retrieves the rows at the given indices from the database of queryType,
+// either with two-server DPF-PIR or, if configured, with single-server LWE-PIR
+func (c *Client) retrieve(queryType database.QueryType, indices []uint32, isSender bool) ([][]byte, error) {
+	if c.SingleServer {
+		return c.retrieveLWE(queryType, indices, isSender)
+	}
+
+	queriesGRPC := make([][]*pb.Query, c.NumServer)
+	for i := 0; i < c.NumServer; i++ {
+		queriesGRPC[i] = make([]*pb.Query, len(indices))
+	}
+	for i, idx := range indices {
+		dpfKeys, _ := c.Dpfs[queryType].Query(int(idx))
+		for k := 0; k < c.NumServer; k++ {
+			queriesGRPC[k][i] = &pb.Query{DpfKey: c.Dpfs[queryType].EncodeKey(dpfKeys[k])}
+		}
+	}
+
+	// Send all queries in parallel to servers
+	ans_grpc := make([]*pb.Answers, c.NumServer)
+	errs := make([]error, c.NumServer)
+
+	var wg sync.WaitGroup
+	wg.Add(c.NumServer)
+	for i := 0; i < int(c.NumServer); i++ {
+		go makeQueriesWorker(c, &wg, i, &queriesGRPC, &ans_grpc, errs, isSender)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	for k, ans := range ans_grpc {
+		if len(ans.Answers) != len(indices) {
+			return nil, fmt.Errorf("server %d sent %d answers for %d queries", k, len(ans.Answers), len(indices))
+		}
+	}
+	rows := make([][]byte, len(indices))
+	for i := range indices {
+		out, err := c.Dpfs[queryType].Reconstruct(
+			[][]byte{ans_grpc[0].Answers[i].Answer,
+				ans_grpc[1].Answers[i].Answer})
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconstruct answer: %v", err)
+		}
+		rows[i] = out
+	}
+	return rows, nil
+}
+
+// Same as retrieve, but all queries are answered by the first server using LWE-PIR
+func (c *Client) retrieveLWE(queryType database.QueryType, indices []uint32, isSender bool) ([][]byte, error) {
+	queriesGRPC := make([]*pb.Query, len(indices))
+	reconstructFuncs := make([]pir.ReconstructFunc, len(indices))
+	for i, idx := range indices {
+		var q *pir.LWEQuery
+		q, reconstructFuncs[i] = c.Lwes[queryType].Query(int(idx))
+		queriesGRPC[i] = &pb.Query{LweQuery: util.Uint32SliceToBytes(q.Vec)}
+	}
 
+	clientDeadline := time.Now().Add(util.TIMEOUT)
+	ctx, cancel := context.WithTimeout(context.Background(), util.TIMEOUT)
+	ctx, cancel = context.WithDeadline(ctx, clientDeadline)
+	defer cancel()
+
+	var ans_grpc *pb.Answers
+	var err error
+	pb_in := &pb.Queries{Queries: queriesGRPC}
+	if isSender {
+		ans_grpc, err = (*c.GrpcClients[0]).MakeLWEKWQueries(ctx, pb_in)
+	} else {
+		ans_grpc, err = (*c.GrpcClients[0]).MakeLWEIQueries(ctx, pb_in)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get row: %w", err)
+	}
+	if isSender {
+		c.BW["SendPIRUp"] += uint32(proto.Size(pb_in))
+		c.BW["SendPIRDown"] += uint32(proto.Size(ans_grpc))
+	} else {
+		c.BW["RecvPIRUp"] += uint32(proto.Size(pb_in))
+		c.BW["RecvPIRDown"] += uint32(proto.Size(ans_grpc))
+	}
+
+	// every answer has M entries
+	if len(ans_grpc.Answers) != len(indices) {
+		return nil, fmt.Errorf("server sent %d answers for %d queries", len(ans_grpc.Answers), len(indices))
+	}
+	rows := make([][]byte, len(indices))
+	for i := range indices {
+		if len(ans_grpc.Answers[i].Answer) != 4*c.Lwes[queryType].M {
+			return nil, fmt.Errorf("LWE answer %d has length %d, expected %d", i, len(ans_grpc.Answers[i].Answer), 4*c.Lwes[queryType].M)
+		}
+		answer, err := util.BytesToUint32Slice(ans_grpc.Answers[i].Answer)
+		if err != nil {
+			return nil, err
+		}
+		if rows[i], err = reconstructFuncs[i]([]interface{}{&pir.LWEQueryResp{Answer: answer}}); err != nil {
+			return nil, fmt.Errorf("failed to reconstruct answer: %v", err)
+		}
+	}
+	return rows, nil
 }
 
-func makeQueriesWorker(c *Client, wg *sync.WaitGroup, id int, queriesGRPC *[][]*pb.Query, ans_grpc *[]*pb.Answers, isSender bool) {
+// errs[id] is set if the server could not be queried
+func makeQueriesWorker(c *Client, wg *sync.WaitGroup, id int, queriesGRPC *[][]*pb.Query, ans_grpc *[]*pb.Answers, errs []error, isSender bool) {
 	defer wg.Done()
 	var err error
 	clientDeadline := time.Now().Add(util.TIMEOUT)
@@ -231,7 +416,8 @@ func makeQueriesWorker(c *Client, wg *sync.WaitGroup, id int, queriesGRPC *[][]*
 	}
 
 	if err != nil {
-		log.Fatalf("could not get row: %v", err)
+		errs[id] = fmt.Errorf("could not get rows from server %d: %w", id, err)
+		return
 	}
 	// BW cost is the same for each server, so we only need to measure it once and multiply by numserver
 	if id == 0 {
@@ -246,20 +432,126 @@ func makeQueriesWorker(c *Client, wg *sync.WaitGroup, id int, queriesGRPC *[][]*
 
 }
 
-func (c *Client) Notify(targets *[]database.IKVElement, isSender bool) {
-	col := notify.CreateVectorIKV(targets, c.Pps[database.Idx].NRows)
-	shares := notify.GenShares(col, c.NumServer, c.DpfSeed)
+func (c *Client) Notify(targets *[]database.IKVElement, isSender bool) error {
+	return c.NotifyWithPayloads(targets, nil, isSender)
+}
 
-	var wg sync.WaitGroup
-	wg.Add(c.NumServer)
+// Like Notify, but payloads[i] is delivered to (*targets)[i] along with the notification.
+// Payloads are only sent if PayloadLen is configured, targets without payload get a zero payload.
+func (c *Client) NotifyWithPayloads(targets *[]database.IKVElement, payloads [][]byte, isSender bool) error {
+	reqs := make([]*pb.NotifyRequest, c.NumServer)
+	for i := range reqs {
+		reqs[i] = &pb.NotifyRequest{Idx: uint32(c.Idx), Vec: &pb.Vector{}}
+	}
+	indices := make([]uint32, len(*targets))
+	for i, target := range *targets {
+		indices[i] = target.Idx
+	}
+	if (c.VerifyColumns || c.DPFNotify) && len(indices) > int(c.RateS) {
+		return fmt.Errorf("%d targets, more than the notification rate %d", len(indices), c.RateS)
+	}
+	if len(payloads) > len(indices) {
+		return errors.New("more payloads than targets")
+	}
+	switch {
+	case c.DPFNotify:
+		// one DPF key per target (or dummy) instead of the column, the servers expand the keys to the parts
+		for i, keys := range notify.GenNotifyKeys(indices, c.Pps[database.Idx].NRows, int(c.RateS)) {
+			reqs[i].Keys = keys
+		}
+	case c.VerifyColumns:
+		// the column is sent as RateS parts with at most one target each, which the servers can check
+		for _, part := range notify.SplitColumn(indices, c.Pps[database.Idx].NRows, int(c.RateS)) {
+			for i, share := range notify.GenShares(part, c.NumServer) {
+				reqs[i].Parts = append(reqs[i].Parts, share)
+			}
+		}
+	default:
+		col := notify.CreateVectorIKV(targets, c.Pps[database.Idx].NRows)
+		for i, share := range notify.GenShares(col, c.NumServer) {
+			reqs[i].Vec.Val = share
+		}
+	}
+	var payloadCol []byte
+	if c.PayloadLen > 0 && c.DPFNotify {
+		for i, keys := range notify.GenPayloadKeys(indices[:len(payloads)], payloads, c.Pps[database.Idx].NRows, int(c.PayloadLen), int(c.RateS)) {
+			reqs[i].PayloadKeys = keys
+		}
+	} else if c.PayloadLen > 0 {
+		payloadCol = notify.CreatePayloadColumn(indices[:len(payloads)], payloads, c.Pps[database.Idx].NRows, int(c.PayloadLen))
+		for i, share := range notify.GenShares(payloadCol, c.NumServer) {
+			reqs[i].Vec.Payload = share
+		}
+	} else if len(payloads) > 0 {
+		return errors.New("payloads are not enabled")
+	}
 
-	for i := 0; i < int(c.NumServer); i++ {
-		go notifyWorker(c, &wg, i, &shares, isSender)
+	// in auth mode every cell of the column carries a MAC of its bit and payload, which receivers check
+	if c.Pps[database.Idx].Auth && c.DPFNotify {
+		seeds, keys := notify.GenMacKeys(indices, payloads, c.Pps[database.Idx].NRows, int(c.PayloadLen), int(c.RateS))
+		for i := range keys {
+			reqs[i].MacSeed, reqs[i].MacKeys = seeds[i], keys[i]
+		}
+	} else if c.Pps[database.Idx].Auth {
+		col := notify.CreateVector(indices, c.Pps[database.Idx].NRows)
+		seeds, tags := notify.GenMacs(col, payloadCol, c.Pps[database.Idx].NRows, int(c.PayloadLen), c.NumServer)
+		for i := range reqs {
+			reqs[i].MacSeed, reqs[i].Vec.Mac = seeds[i], tags[i]
+		}
+	}
+
+	// both servers have to write the shares to the same epoch, if a round flip happened in between
+	// the request is repeated for the new epoch (the servers drop the shares written to the old one)
+	acks := make([]*pb.Ack, c.NumServer)
+	for attempt := 0; ; attempt++ {
+		// the servers match their verifications of a write by its id
+		writeID := make([]byte, 16)
+		if _, err := util.RandomPRG().Read(writeID); err != nil {
+			return fmt.Errorf("could not sample write id: %v", err)
+		}
+		for _, req := range reqs {
+			req.Epoch = c.Epoch
+			if c.VerifyColumns {
+				req.WriteId = writeID
+			}
+		}
+		// every attempt needs fresh triples, a server may have verified the last one already
+		if c.VerifyColumns {
+			for i, triples := range notify.GenTriples(int(c.RateS)) {
+				reqs[i].Triples = notify.EncodeTriples(triples)
+			}
+		}
+
+		// a server may have written its share before another one failed, notifying again replaces the column
+		errs := make([]error, c.NumServer)
+		var wg sync.WaitGroup
+		wg.Add(c.NumServer)
+
+		for i := 0; i < int(c.NumServer); i++ {
+			go notifyWorker(c, &wg, i, reqs, &acks, errs, isSender)
+		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+
+		ok := true
+		for _, ack := range acks {
+			ok = ok && ack.Ok
+			c.Epoch = max(c.Epoch, ack.Epoch)
+		}
+		if ok {
+			return nil
+		}
+		if attempt == util.MAX_NOTIFY_ATTEMPTS-1 {
+			return errors.New("could not write column, servers are in different epochs")
+		}
+		time.Sleep(util.NOTIFY_RETRY_DELAY)
 	}
-	wg.Wait()
 }
 
-func notifyWorker(c *Client, wg *sync.WaitGroup, id int, shares *[][]byte, isSender bool) {
+// errs[id] is set if the server could not be reached or rejected the column
+func notifyWorker(c *Client, wg *sync.WaitGroup, id int, reqs []*pb.NotifyRequest, acks *[]*pb.Ack, errs []error, isSender bool) {
 	defer wg.Done()
 
 	clientDeadline := time.Now().Add(util.TIMEOUT)
@@ -267,11 +559,13 @@ func notifyWorker(c *Client, wg *sync.WaitGroup, id int, shares *[][]byte, isSen
 	ctx, cancel = context.WithDeadline(ctx, clientDeadline)
 	defer cancel()
 
-	pb_in := &pb.NotifyRequest{Idx: uint32(c.Idx), Vec: &pb.Vector{Val: (*shares)[id]}}
+	pb_in := reqs[id]
 	pb_out, err := (*c.GrpcClients[id]).SetColumn(ctx, pb_in)
 	if err != nil {
-		log.Fatalf("could not write column: %v", err)
+		errs[id] = fmt.Errorf("could not write column to server %d: %w", id, err)
+		return
 	}
+	(*acks)[id] = pb_out
 
 	if id == 0 {
 		if isSender {
@@ -285,22 +579,104 @@ func notifyWorker(c *Client, wg *sync.WaitGroup, id int, shares *[][]byte, isSen
 
 }
 
-func (c *Client) GetNotified(isSender bool) []uint32 {
-	shares := make([][]byte, c.NumServer)
+/*
+Returns the senders that notified the client. In auth mode the senders whose cells do not match their MACs
+are dropped and reported in a *MacError, the returned senders are authentic.
+*/
+func (c *Client) GetNotified(isSender bool) ([]uint32, error) {
+	senders, _, err := c.GetNotifiedWithPayloads(isSender)
+	return senders, err
+}
 
-	var wg sync.WaitGroup
-	wg.Add(c.NumServer)
-	for i := 0; i < int(c.NumServer); i++ {
-		go getNotifiedWorker(c, &wg, i, &shares, isSender)
+/*
+Like GetNotified, but also returns the payload each sender left, if PayloadLen is configured.
+In auth mode the row is checked against the MACs of its cells, see GetNotified.
+*/
+func (c *Client) GetNotifiedWithPayloads(isSender bool) ([]uint32, [][]byte, error) {
+	shares := make([][]byte, c.NumServer)
+	payloadShares := make([][]byte, c.NumServer)
+	macShares := make([][]byte, c.NumServer)
+	epochs := make([]uint64, c.NumServer)
+
+	// shares of different epochs do not fit together, which happens if the request crosses a round flip
+	for attempt := 0; ; attempt++ {
+		errs := make([]error, c.NumServer)
+		var wg sync.WaitGroup
+		wg.Add(c.NumServer)
+		for i := 0; i < int(c.NumServer); i++ {
+			go getNotifiedWorker(c, &wg, i, &shares, &payloadShares, &macShares, &epochs, errs, isSender)
+		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			return nil, nil, err
+		}
+		if !slices.ContainsFunc(epochs, func(e uint64) bool { return e != epochs[0] }) {
+			break
+		}
+		if attempt == util.MAX_NOTIFY_ATTEMPTS-1 {
+			return nil, nil, errors.New("could not get row, servers are in different epochs")
+		}
+		time.Sleep(util.NOTIFY_RETRY_DELAY)
 	}
-	wg.Wait()
 	row := notify.CombineShares(shares)
+	var payloadRow []byte
+	if c.PayloadLen > 0 {
+		payloadRow = notify.CombineShares(payloadShares)
+	}
+	failed, err := c.verifyRow(row, payloadRow, macShares)
+	if err != nil {
+		return nil, nil, err
+	}
 	//Get sender indices from row
 	senders := notify.ReadVector(row)
 
-	return senders
+	var payloads [][]byte
+	if c.PayloadLen > 0 {
+		payloads = notify.ReadPayloads(payloadRow, senders, int(c.PayloadLen))
+	}
+	if len(failed) > 0 {
+		return senders, payloads, &MacError{Senders: failed}
+	}
+	return senders, payloads, nil
+}
+
+/*
+Reported by GetNotified and its variants in auth mode if cells of the client's row do not match their MACs:
+a server tampered with the row, or a sender wrote a wrong cell on purpose. The notifications of these
+senders are dropped, the other senders are authentic and returned along with the error.
+*/
+type MacError struct {
+	Senders []uint32 // the dropped senders
+}
+
+func (e *MacError) Error() string {
+	return fmt.Sprintf("%v: dropped the notifications of senders %v", notify.ErrMac, e.Senders)
 }
-func getNotifiedWorker(c *Client, wg *sync.WaitGroup, id int, shares *[][]byte, isSender bool) {
+
+func (e *MacError) Unwrap() error {
+	return notify.ErrMac
+}
+
+/*
+In auth mode, drops the notifications of senders whose cells do not match their MACs from a reconstructed row
+(and its payload row, nil without payloads) and returns these senders.
+Rows of the wrong length are an error, nothing in the row can be trusted then.
+*/
+func (c *Client) verifyRow(row []byte, payloadRow []byte, macShares [][]byte) ([]uint32, error) {
+	if !c.Pps[database.Idx].Auth {
+		return nil, nil
+	}
+	failed, err := notify.VerifyRow(row, payloadRow, notify.CombineShares(macShares), int(c.PayloadLen))
+	if err != nil && len(failed) == 0 {
+		return nil, fmt.Errorf("reject received notifications: %w", err)
+	}
+	for _, sender := range failed {
+		row[sender/8] &^= 0x80 >> (sender % 8)
+	}
+	return failed, nil
+}
+
+func getNotifiedWorker(c *Client, wg *sync.WaitGroup, id int, shares *[][]byte, payloadShares *[][]byte, macShares *[][]byte, epochs *[]uint64, errs []error, isSender bool) {
 	defer wg.Done()
 
 	// Contact the server and print out its response.
@@ -311,9 +687,13 @@ func getNotifiedWorker(c *Client, wg *sync.WaitGroup, id int, shares *[][]byte,
 	pb_in := &pb.Index{Idx: uint32(c.Idx)}
 	sharedRow, err := (*c.GrpcClients[id]).GetRow(ctx, pb_in)
 	if err != nil {
-		log.Fatalf("could not get row: %v", err)
+		errs[id] = fmt.Errorf("could not get row from server %d: %w", id, err)
+		return
 	}
 	(*shares)[id] = sharedRow.Val
+	(*payloadShares)[id] = sharedRow.Payload
+	(*macShares)[id] = sharedRow.Mac
+	(*epochs)[id] = sharedRow.Epoch
 	if id == 0 {
 		if isSender {
 			c.BW["SendGetNotifiedUp"] += uint32(proto.Size(pb_in)) * uint32(c.NumServer)
@@ -325,63 +705,139 @@ func getNotifiedWorker(c *Client, wg *sync.WaitGroup, id int, shares *[][]byte,
 	}
 }
 
-// Do index PIR for (all) senders based on retrieval rate
-func (c *Client) GetSenders(senders []uint32) *[]database.IKVElement {
-	// Client has to make fixed number of requests (rateR many)
-	// generate dummy queries based on own idx
-	for i := 0; i < int(c.RateR)-len(senders); i++ {
-		senders = append(senders, c.Idx)
+/*
+Like GetNotifiedWithPayloads, but returns only notifications that were not returned by an earlier call,
+read from all epochs since the last call that have not expired yet. In auth mode the rows are checked as well.
+Payloads are nil if PayloadLen is not configured.
+*/
+func (c *Client) GetNewNotifications(isSender bool) ([]uint32, [][]byte, error) {
+	res := make([]*pb.EpochRows, c.NumServer)
+
+	// every publication reshares the rows, shares read before and after a publication do not fit together
+	for attempt := 0; ; attempt++ {
+		errs := make([]error, c.NumServer)
+		var wg sync.WaitGroup
+		wg.Add(c.NumServer)
+		for i := 0; i < int(c.NumServer); i++ {
+			go getRowsWorker(c, &wg, i, &res, errs, isSender)
+		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			return nil, nil, err
+		}
+		if !slices.ContainsFunc(res, func(r *pb.EpochRows) bool { return r.Published != res[0].Published }) {
+			break
+		}
+		if attempt == util.MAX_NOTIFY_ATTEMPTS-1 {
+			return nil, nil, errors.New("could not get rows, servers published different epochs")
+		}
+		time.Sleep(util.NOTIFY_RETRY_DELAY)
 	}
-	queriesGRPC := make([][]*pb.Query, c.NumServer)
-	// generate all queries, reconstruct is just always the same here
-	for _, sender := range senders {
-		// generate dpf queries
-		dpfKeys, _ := c.Dpfs[database.Idx].Query(int(sender))
-		for k, key := range dpfKeys {
-			var q_grpc = pb.Query{}
-			q_grpc.DpfKey = (*key)[:]
-			queriesGRPC[k] = append(queriesGRPC[k], &q_grpc)
+
+	// during a round flip the servers can be one epoch apart, only the epochs both returned are read
+	from, end, current := res[0].From, res[0].From+uint64(len(res[0].Rows)), res[0].Current
+	for _, r := range res[1:] {
+		from = max(from, r.From)
+		end = min(end, r.From+uint64(len(r.Rows)))
+		current = min(current, r.Current)
+	}
+	c.Epoch = max(c.Epoch, current)
+
+	var rows, payloadRows [][]byte
+	var failed []uint32
+	for e := from; e < end; e++ {
+		shares := make([][]byte, c.NumServer)
+		payloadShares := make([][]byte, c.NumServer)
+		macShares := make([][]byte, c.NumServer)
+		for i := range res {
+			shares[i] = res[i].Rows[e-res[i].From].Val
+			payloadShares[i] = res[i].Rows[e-res[i].From].Payload
+			macShares[i] = res[i].Rows[e-res[i].From].Mac
+		}
+		row := notify.CombineShares(shares)
+		var payloadRow []byte
+		if c.PayloadLen > 0 {
+			payloadRow = notify.CombineShares(payloadShares)
 		}
+		// the mailbox is not advanced, so the epochs are read again by the next call
+		dropped, err := c.verifyRow(row, payloadRow, macShares)
+		if err != nil {
+			return nil, nil, err
+		}
+		failed = append(failed, dropped...)
+		rows = append(rows, row)
+		payloadRows = append(payloadRows, payloadRow)
 	}
-	// Send all queries in parallel to servers
-	ans_grpc := make([]*pb.Answers, c.NumServer)
+	senders, epochs := c.Mailbox.Read(from, rows, current)
 
-	var wg sync.WaitGroup
-	wg.Add(c.NumServer)
-	for i := 0; i < int(c.NumServer); i++ {
-		go makeQueriesWorker(c, &wg, i, &queriesGRPC, &ans_grpc, false)
+	var payloads [][]byte
+	if c.PayloadLen > 0 {
+		payloads = make([][]byte, len(senders))
+		for i, sender := range senders {
+			payloads[i] = notify.ReadPayloads(payloadRows[epochs[i]-from], []uint32{sender}, int(c.PayloadLen))[0]
+		}
+	}
+	if len(failed) > 0 {
+		return senders, payloads, &MacError{Senders: failed}
+	}
+	return senders, payloads, nil
+}
+
+func getRowsWorker(c *Client, wg *sync.WaitGroup, id int, res *[]*pb.EpochRows, errs []error, isSender bool) {
+	defer wg.Done()
+
+	clientDeadline := time.Now().Add(util.TIMEOUT)
+	ctx, cancel := context.WithTimeout(context.Background(), util.TIMEOUT)
+	ctx, cancel = context.WithDeadline(ctx, clientDeadline)
+	defer cancel()
+	pb_in := &pb.EpochIndex{Idx: uint32(c.Idx), From: c.Mailbox.Next, To: math.MaxUint64}
+	var err error
+	(*res)[id], err = (*c.GrpcClients[id]).GetRows(ctx, pb_in)
+	if err != nil {
+		errs[id] = fmt.Errorf("could not get rows from server %d: %w", id, err)
+		return
+	}
+	if id == 0 {
+		if isSender {
+			c.BW["SendGetNotifiedUp"] += uint32(proto.Size(pb_in)) * uint32(c.NumServer)
+			c.BW["SendGetNotifiedDown"] += uint32(proto.Size((*res)[id])) * uint32(c.NumServer)
+		} else {
+			c.BW["RecvGetNotifiedUp"] += uint32(proto.Size(pb_in)) * uint32(c.NumServer)
+			c.BW["RecvGetNotifiedDown"] += uint32(proto.Size((*res)[id])) * uint32(c.NumServer)
+		}
+	}
+}
+
+/*
+Do index PIR for the senders based on retrieval rate: the client always makes RateR queries,
+senders that do not fit are queued like in GetReceiverInfo and retrieved by the following calls.
+*/
+func (c *Client) GetSenders(senders []uint32) (*[]database.IKVElement, error) {
+	senders = takeQueued(&c.senderQueue, senders, int(c.RateR), func(a, b uint32) bool { return a == b })
+	// Client has to make fixed number of requests (rateR many)
+	// generate dummy queries based on own idx
+	for len(senders) < int(c.RateR) {
+		senders = append(senders, c.Idx)
+	}
+	rows, err := c.retrieve(database.Idx, senders, false)
+	if err != nil {
+		return nil, err
 	}
-	wg.Wait()
 
 	var senderData []database.IKVElement
 	for i, senderIdx := range senders {
-		if !c.Pps[database.Idx].Auth && senderIdx != c.Idx {
-			out, err := c.Dpfs[database.Idx].Reconstruct(
-				[][]byte{ans_grpc[0].Answers[i].Answer,
-					ans_grpc[1].Answers[i].Answer})
-			if err != nil {
-				log.Fatalf("failed to reconstruct answer")
-			}
-			senderData = append(senderData, c.Pps[database.Idx].RowToIKV(senderIdx, out))
-		}
+		out := rows[i]
 		if c.Pps[database.Idx].Auth {
 			// all queries in auth case have to be checked to ensure server learns nothing
-			out, err := c.Dpfs[database.Idx].Reconstruct(
-				[][]byte{ans_grpc[0].Answers[i].Answer,
-					ans_grpc[1].Answers[i].Answer})
-			if err != nil {
-				log.Fatalf("failed to reconstruct answer")
-			}
-			// Verify proof
-			data, err := c.Pps[database.Idx].VerifyRow(out)
+			out, err = c.Pps[database.Idx].VerifyRow(out)
 			if err != nil {
-				log.Fatalf("reject received pir answers, proof rejected")
-			}
-			// if it was not a dummy query, add the info to the sender list
-			if senderIdx != c.Idx {
-				senderData = append(senderData, c.Pps[database.Idx].RowToIKV(senderIdx, data))
+				return nil, fmt.Errorf("reject received pir answers, proof rejected: %v", err)
 			}
 		}
+		// if it was not a dummy query, add the info to the sender list
+		if senderIdx != c.Idx {
+			senderData = append(senderData, c.Pps[database.Idx].RowToIKV(senderIdx, out))
+		}
 	}
-	return &senderData
+	return &senderData, nil
 }
@@ -0,0 +1,206 @@
+package bootstrapping
+
+import (
+	"context"
+	"errors"
+	"sabot/lib/database"
+	"sabot/lib/notify"
+	"sabot/lib/pir"
+	"sabot/lib/util"
+	pb "sabot/proto/bootstrapping"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestTakeQueued(t *testing.T) {
+	equal := func(a, b uint32) bool { return a == b }
+	var queue []uint32
+
+	// more items than queries, the rest is queued
+	if got := takeQueued(&queue, []uint32{1, 2, 3, 4, 5}, 2, equal); !slices.Equal(got, []uint32{1, 2}) {
+		t.Fatal("wrong first batch", got)
+	}
+	// queued items come first, items already queued are not queued twice
+	if got := takeQueued(&queue, []uint32{5, 6}, 2, equal); !slices.Equal(got, []uint32{3, 4}) {
+		t.Fatal("wrong second batch", got)
+	}
+	if !slices.Equal(queue, []uint32{5, 6}) {
+		t.Fatal("wrong queue", queue)
+	}
+	if got := takeQueued(&queue, nil, 3, equal); !slices.Equal(got, []uint32{5, 6}) || len(queue) != 0 {
+		t.Fatal("queue not drained", got, queue)
+	}
+	if got := takeQueued(&queue, nil, 3, equal); len(got) != 0 {
+		t.Fatal("items from an empty queue", got)
+	}
+}
+
+func TestClientMacs(t *testing.T) {
+	size, payloadLen := 50, 4
+	var tamper func(*pb.Vector)
+	clients := make([]*pb.BootstrappingClient, 2)
+	for i := range clients {
+		s := &Server{MultiClient: false, NumThreads: 1, Notifications: notify.NewEpochMatrix(size, payloadLen, notify.Schedule{})}
+		s.Notifications.Macs = true
+		l := localServer{s: s}
+		// only the first server tampers with the rows
+		if i == 0 {
+			l.tamper = func(row *pb.Vector) {
+				if tamper != nil {
+					tamper(row)
+				}
+			}
+		}
+		var client pb.BootstrappingClient = l
+		clients[i] = &client
+	}
+	newClient := func(idx uint32) *Client {
+		return &Client{
+			Experiment: NewExperiment(&Config{Idx: idx, PayloadLen: uint32(payloadLen)}),
+			Pps:        []*database.DBParams{{NRows: uint32(size), Auth: true}},
+			NumServer:  2,
+			ServerInfo: &ServerInfo{GrpcClients: clients},
+		}
+	}
+	receiver := newClient(17)
+	if err := newClient(3).NotifyWithPayloads(&[]database.IKVElement{{Idx: 17}}, [][]byte{{1, 2}}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := newClient(4).Notify(&[]database.IKVElement{{Idx: 17}}, true); err != nil {
+		t.Fatal(err)
+	}
+	senders, payloads, err := receiver.GetNotifiedWithPayloads(false)
+	if err != nil || !slices.Equal(senders, []uint32{3, 4}) || payloads[0][1] != 2 {
+		t.Fatal("wrong senders", senders, payloads, err)
+	}
+
+	// a server that flips a bit is reported, the other senders are still returned
+	tamper = func(row *pb.Vector) { row.Val[0] ^= 0x80 >> 3 }
+	senders, err = receiver.GetNotified(false)
+	var macErr *MacError
+	if !errors.As(err, &macErr) || !errors.Is(err, notify.ErrMac) || !slices.Equal(macErr.Senders, []uint32{3}) {
+		t.Fatal("expected error for tampered row, got", err)
+	}
+	if !slices.Equal(senders, []uint32{4}) {
+		t.Fatal("wrong authentic senders", senders)
+	}
+	if senders, _, err := receiver.GetNewNotifications(false); !errors.As(err, &macErr) || !slices.Equal(senders, []uint32{4}) {
+		t.Fatal("expected error for tampered rows, got", senders, err)
+	}
+
+	// so is a server that changes a payload
+	tamper = func(row *pb.Vector) { row.Payload[3*payloadLen+1] ^= 1 }
+	if senders, _, err := receiver.GetNotifiedWithPayloads(false); !errors.As(err, &macErr) || !slices.Equal(macErr.Senders, []uint32{3}) || !slices.Equal(senders, []uint32{4}) {
+		t.Fatal("expected error for tampered payload, got", senders, err)
+	}
+
+	// a MAC row of the wrong length is an error instead of a crash
+	tamper = func(row *pb.Vector) { row.Mac = row.Mac[1:] }
+	if senders, err := receiver.GetNotified(false); err == nil || errors.As(err, &macErr) || len(senders) != 0 {
+		t.Fatal("expected error for malformed MACs, got", senders, err)
+	}
+}
+
+// answers LWE requests with the given (malformed) hint and answers
+type lweServer struct {
+	pb.BootstrappingClient
+	hint    *pb.LWEHint
+	answers *pb.Answers
+}
+
+func (l lweServer) GetLWEHint(ctx context.Context, in *pb.HintRequest, opts ...grpc.CallOption) (*pb.LWEHint, error) {
+	return l.hint, nil
+}
+
+func (l lweServer) MakeLWEIQueries(ctx context.Context, in *pb.Queries, opts ...grpc.CallOption) (*pb.Answers, error) {
+	return l.answers, nil
+}
+
+func TestClientLWE(t *testing.T) {
+	s := Server{ContactDB: &database.ContactDB{DBType: database.TwoDB}, MultiClient: false, NumThreads: 1}
+	s.ContactDB.Setup(database.GetTestData(100, uint(util.KEY_LENGTH), uint(util.VAL_LENGTH), 42), false)
+	s.SetupLWE()
+	pp, hint, err := s.GetLWEHint(database.Idx, 0, uint32(s.LWEDBs[database.Idx].M))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var client pb.BootstrappingClient = lweServer{}
+	c := &Client{
+		Experiment: NewExperiment(&Config{SingleServer: true}),
+		Lwes:       []*pir.LWEClient{pir.InitLWEClient(pp, hint)},
+		NumServer:  1,
+		ServerInfo: &ServerInfo{GrpcClients: []*pb.BootstrappingClient{&client}},
+	}
+
+	// a server that sends malformed answers is an error instead of a crash
+	query, _ := c.Lwes[database.Idx].Query(7)
+	answers, err := s.AnswerLWEIQueries(&pb.Queries{Queries: []*pb.Query{{LweQuery: util.Uint32SliceToBytes(query.Vec)}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tampered := range []*pb.Answers{
+		{},
+		{Answers: []*pb.Answer{{Answer: answers[0].Answer[1:]}}},
+		{Answers: []*pb.Answer{{Answer: answers[0].Answer[4:]}}},
+	} {
+		client = lweServer{answers: tampered}
+		if _, err := c.retrieveLWE(database.Idx, []uint32{7}, false); err == nil {
+			t.Fatal("expected error for malformed answers")
+		}
+	}
+
+	// so is a hint that does not fit the parameters
+	params := &pb.LWEParams{N: pp.N, NumRecords: uint32(pp.NumRecords), RowLen: uint32(pp.RowLen), NumCols: uint32(pp.NumCols), M: uint32(pp.M), Seed: pp.Seed[:]}
+	for _, tampered := range []*pb.LWEHint{
+		{Params: params, Hint: []byte{1, 2, 3}},
+		{Params: &pb.LWEParams{M: 1}},
+	} {
+		client = lweServer{hint: tampered}
+		if _, err := c.getLWEHint(database.Idx); err == nil {
+			t.Fatal("expected error for malformed hint")
+		}
+	}
+}
+
+// a server that cannot be reached
+type downServer struct {
+	pb.BootstrappingClient
+}
+
+var errDown = errors.New("server down")
+
+func (downServer) SetColumn(ctx context.Context, in *pb.NotifyRequest, opts ...grpc.CallOption) (*pb.Ack, error) {
+	return nil, errDown
+}
+
+func (downServer) GetRow(ctx context.Context, in *pb.Index, opts ...grpc.CallOption) (*pb.Vector, error) {
+	return nil, errDown
+}
+
+func (downServer) GetRows(ctx context.Context, in *pb.EpochIndex, opts ...grpc.CallOption) (*pb.EpochRows, error) {
+	return nil, errDown
+}
+
+func TestClientServerDown(t *testing.T) {
+	size := 50
+	var up pb.BootstrappingClient = localServer{s: &Server{MultiClient: false, NumThreads: 1, Notifications: notify.NewEpochMatrix(size, 0, notify.Schedule{})}}
+	var down pb.BootstrappingClient = downServer{}
+	c := &Client{
+		Experiment: NewExperiment(&Config{Idx: 3}),
+		Pps:        []*database.DBParams{{NRows: uint32(size)}},
+		NumServer:  2,
+		ServerInfo: &ServerInfo{GrpcClients: []*pb.BootstrappingClient{&up, &down}},
+	}
+	// the error of the server is returned instead of ending the process
+	if err := c.Notify(&[]database.IKVElement{{Idx: 17}}, true); !errors.Is(err, errDown) {
+		t.Fatal("expected error for unreachable server, got", err)
+	}
+	if _, err := c.GetNotified(false); !errors.Is(err, errDown) {
+		t.Fatal("expected error for unreachable server, got", err)
+	}
+	if _, _, err := c.GetNewNotifications(false); !errors.Is(err, errDown) {
+		t.Fatal("expected error for unreachable server, got", err)
+	}
+}
@@ -0,0 +1,199 @@
+package bootstrapping
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sabot/lib/notify"
+	"sabot/lib/util"
+	pb "sabot/proto/bootstrapping"
+	"sync"
+	"time"
+)
+
+/*
+Connection to the other server, used to verify column writes together (see notify.SketchVerifier)
+and to exchange the randomness of the resharing (see notify.EpochMatrix.ReshareNonce).
+Both servers send their messages of a verification round with Send and receive the messages of
+the other server with Receive, messages are matched by the write id the client chose.
+The messages name the column and epoch of the write, a server rejects the write if they are
+not the ones it verifies (the client sent different requests to the servers).
+All messages are authenticated with the key the servers share, the key also derives the sketches.
+A server that does not verify a write (because it is for another epoch) sends a message of round 0
+instead, so the other server stops waiting for it.
+*/
+type Peer struct {
+	Key  []byte
+	Send func(*pb.SketchMessage) error
+	// asks the other server for its resharing randomness
+	GetNonce func(*pb.ReshareNonceRequest) (*pb.ReshareNonce, error)
+
+	mu      sync.Mutex
+	pending map[string]*pendingSketch
+}
+
+// returned (wrapped) by VerifyColumn if the other server did not verify the write
+var errSketchAborted = errors.New("the other server aborted the verification")
+
+type pendingSketch struct {
+	values  chan *pb.SketchMessage
+	created time.Time
+}
+
+func NewPeer(key []byte, send func(*pb.SketchMessage) error, getNonce func(*pb.ReshareNonceRequest) (*pb.ReshareNonce, error)) *Peer {
+	return &Peer{Key: key, Send: send, GetNonce: getNonce, pending: make(map[string]*pendingSketch)}
+}
+
+func (p *Peer) mac(in *pb.SketchMessage) []byte {
+	mac := hmac.New(sha256.New, p.Key)
+	mac.Write([]byte("sabot peer"))
+	msg := binary.LittleEndian.AppendUint32(nil, uint32(len(in.WriteId)))
+	msg = append(msg, in.WriteId...)
+	msg = binary.LittleEndian.AppendUint32(msg, in.Round)
+	msg = binary.LittleEndian.AppendUint32(msg, in.ServerID)
+	msg = binary.LittleEndian.AppendUint32(msg, in.Idx)
+	msg = binary.LittleEndian.AppendUint64(msg, in.Epoch)
+	for _, v := range in.Values {
+		msg = binary.LittleEndian.AppendUint64(msg, v)
+	}
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// authenticates a request for resharing randomness (nonce is nil) or the answer
+func (p *Peer) nonceMac(e uint64, serverID uint32, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, p.Key)
+	mac.Write([]byte("sabot reshare nonce"))
+	msg := binary.LittleEndian.AppendUint64(nil, e)
+	msg = binary.LittleEndian.AppendUint32(msg, serverID)
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(nonce)))
+	mac.Write(append(msg, nonce...))
+	return mac.Sum(nil)
+}
+
+// ReshareNonce gets the resharing randomness of epoch e from the other server, which is server 1 - serverID
+func (p *Peer) ReshareNonce(e uint64, serverID int) ([]byte, error) {
+	req := &pb.ReshareNonceRequest{Epoch: e, ServerID: uint32(serverID)}
+	req.Mac = p.nonceMac(e, req.ServerID, nil)
+	out, err := p.GetNonce(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not get resharing randomness of the other server: %v", err)
+	}
+	if out.Epoch != e || out.ServerID != uint32(1-serverID) || len(out.Nonce) == 0 || !hmac.Equal(out.Mac, p.nonceMac(e, out.ServerID, out.Nonce)) {
+		return nil, errors.New("resharing randomness of the other server is not authenticated")
+	}
+	return out.Nonce, nil
+}
+
+// CheckNonceRequest checks that a request for the resharing randomness of this server comes from the other server
+func (p *Peer) CheckNonceRequest(in *pb.ReshareNonceRequest, serverID int) error {
+	if in.ServerID != uint32(1-serverID) || !hmac.Equal(in.Mac, p.nonceMac(in.Epoch, in.ServerID, nil)) {
+		return fmt.Errorf("%w: request for resharing randomness is not authenticated", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// AnswerNonce returns the authenticated answer with the resharing randomness of this server for epoch e
+func (p *Peer) AnswerNonce(e uint64, serverID int, nonce []byte) *pb.ReshareNonce {
+	out := &pb.ReshareNonce{Epoch: e, ServerID: uint32(serverID), Nonce: nonce}
+	out.Mac = p.nonceMac(e, out.ServerID, nonce)
+	return out
+}
+
+// returns the slot for the message of a round, messages are kept until they are picked up or time out
+func (p *Peer) slot(writeID []byte, round uint32) *pendingSketch {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, pending := range p.pending {
+		if time.Since(pending.created) > 2*util.SKETCH_TIMEOUT {
+			delete(p.pending, id)
+		}
+	}
+	id := fmt.Sprintf("%x/%d", writeID, round)
+	pending, ok := p.pending[id]
+	if !ok {
+		pending = &pendingSketch{values: make(chan *pb.SketchMessage, 1), created: time.Now()}
+		p.pending[id] = pending
+	}
+	return pending
+}
+
+func (p *Peer) done(writeID []byte, round uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, fmt.Sprintf("%x/%d", writeID, round))
+}
+
+// Receive takes a message of the other server, which is server 1 - serverID
+func (p *Peer) Receive(in *pb.SketchMessage, serverID int) error {
+	if in.ServerID != uint32(1-serverID) || !hmac.Equal(in.Mac, p.mac(in)) {
+		return fmt.Errorf("%w: sketch message is not authenticated", ErrInvalidRequest)
+	}
+	// an abort replaces the first message of the other server
+	round := max(in.Round, 1)
+	select {
+	case p.slot(in.WriteId, round).values <- in:
+		return nil
+	default:
+		return fmt.Errorf("%w: repeated sketch message", ErrInvalidRequest)
+	}
+}
+
+// sends the values of a round to the other server and waits for its values of the round
+func (p *Peer) exchange(writeID []byte, idx uint32, epoch uint64, round uint32, serverID int, values []uint64) ([]uint64, error) {
+	pending := p.slot(writeID, round)
+	defer p.done(writeID, round)
+	out := &pb.SketchMessage{WriteId: writeID, Round: round, ServerID: uint32(serverID), Values: values, Idx: idx, Epoch: epoch}
+	out.Mac = p.mac(out)
+	if err := p.Send(out); err != nil {
+		return nil, fmt.Errorf("could not send sketch: %v", err)
+	}
+	select {
+	case peer := <-pending.values:
+		if peer.Round == 0 {
+			return nil, errSketchAborted
+		}
+		if peer.Idx != idx || peer.Epoch != epoch {
+			return nil, fmt.Errorf("%w: the other server verifies column %d of epoch %d", ErrInvalidRequest, peer.Idx, peer.Epoch)
+		}
+		return peer.Values, nil
+	case <-time.After(util.SKETCH_TIMEOUT):
+		return nil, fmt.Errorf("no sketch from the other server for round %d", round)
+	}
+}
+
+// Abort tells the other server that this server does not verify the write
+func (p *Peer) Abort(writeID []byte, serverID int) error {
+	out := &pb.SketchMessage{WriteId: writeID, Round: 0, ServerID: uint32(serverID)}
+	out.Mac = p.mac(out)
+	return p.Send(out)
+}
+
+/*
+Checks together with the other server that every part of the write to column idx of the epoch
+has at most one bit set, returns the column, the XOR of the parts. Both servers have to verify the same write.
+*/
+func (p *Peer) VerifyColumn(serverID int, numRows int, writeID []byte, idx uint32, epoch uint64, parts [][]byte, triples []notify.Triple) ([]byte, error) {
+	v, err := notify.NewSketchVerifier(serverID, numRows, notify.SketchKey(p.Key, writeID, idx, epoch), parts, triples)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	peer1, err := p.exchange(writeID, idx, epoch, 1, serverID, v.Round1())
+	if err != nil {
+		return nil, err
+	}
+	own2, err := v.Round2(peer1)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	peer2, err := p.exchange(writeID, idx, epoch, 2, serverID, own2)
+	if err != nil {
+		return nil, err
+	}
+	if !v.Verify(own2, peer2) {
+		return nil, fmt.Errorf("%w: column has a part with more than one target", ErrInvalidRequest)
+	}
+	return notify.CombineParts(parts, numRows)
+}
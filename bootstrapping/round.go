@@ -0,0 +1,180 @@
+package bootstrapping
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sabot/lib/notify"
+	"sabot/lib/util"
+	pb "sabot/proto/bootstrapping"
+	"slices"
+	"time"
+)
+
+func writesToPB(epoch uint64, writes map[uint32]uint32) *pb.RoundWrites {
+	out := &pb.RoundWrites{Epoch: epoch, Columns: make([]uint32, 0, len(writes)), Counts: make([]uint32, 0, len(writes))}
+	for c := range writes {
+		out.Columns = append(out.Columns, c)
+	}
+	slices.Sort(out.Columns)
+	for _, c := range out.Columns {
+		out.Counts = append(out.Counts, writes[c])
+	}
+	return out
+}
+
+func writesFromPB(in *pb.RoundWrites) map[uint32]uint32 {
+	writes := make(map[uint32]uint32, len(in.Columns))
+	for i, c := range in.Columns {
+		writes[c] = in.Counts[i]
+	}
+	return writes
+}
+
+/*
+Authenticates a round control request of the coordinator with the round key, label names the RPC
+so a request cannot be replayed to another one. Publications also cover the published writes.
+*/
+func roundMac(key []byte, label string, sent int64, writes *pb.RoundWrites) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("sabot round " + label))
+	mac.Write(binary.LittleEndian.AppendUint64(nil, uint64(sent)))
+	if writes != nil {
+		buf := binary.LittleEndian.AppendUint64(nil, writes.Epoch)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(len(writes.Columns)))
+		for i, c := range writes.Columns {
+			buf = binary.LittleEndian.AppendUint32(buf, c)
+			if i < len(writes.Counts) {
+				buf = binary.LittleEndian.AppendUint32(buf, writes.Counts[i])
+			}
+		}
+		mac.Write(buf)
+	}
+	return mac.Sum(nil)
+}
+
+func roundRequest(key []byte, label string) *pb.RoundRequest {
+	sent := time.Now().Unix()
+	return &pb.RoundRequest{Time: sent, Mac: roundMac(key, label, sent, nil)}
+}
+
+func publication(key []byte, in *pb.RoundWrites) *pb.RoundWrites {
+	out := &pb.RoundWrites{Epoch: in.Epoch, Columns: in.Columns, Counts: in.Counts, Time: time.Now().Unix()}
+	out.Mac = roundMac(key, "publish", out.Time, out)
+	return out
+}
+
+/*
+FlipRound makes the current epoch of all servers readable and starts the next one.
+First all servers close the epoch for writing, then it is published on all servers
+with only the columns that were written equally often on every server, so a sender
+whose request crossed the flip does not leave a lone share behind.
+Servers that reshare their notifications need the same resharing key, otherwise nothing is published.
+They ratchet the key with randomness they exchange among each other (see Server.PublishRound).
+An interrupted flip is completed by the next call, also if a server restarted in between.
+All requests are authenticated with the round key the servers got with -roundkey.
+*/
+func FlipRound(servers []*pb.BootstrappingClient, key []byte) (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), util.TIMEOUT)
+	defer cancel()
+
+	if err := recoverRound(ctx, servers, key); err != nil {
+		return 0, err
+	}
+
+	closed := make([]*pb.RoundWrites, len(servers))
+	for i, server := range servers {
+		var err error
+		if closed[i], err = (*server).CloseRound(ctx, roundRequest(key, "close")); err != nil {
+			return 0, fmt.Errorf("could not close round on server %d: %v", i, err)
+		}
+		if closed[i].Epoch != closed[0].Epoch {
+			return 0, fmt.Errorf("servers closed different epochs: %d and %d", closed[0].Epoch, closed[i].Epoch)
+		}
+		// differently reshared shares would reconstruct to garbage
+		if !bytes.Equal(closed[i].ReshareTag, closed[0].ReshareTag) {
+			return 0, fmt.Errorf("servers 0 and %d do not reshare with the same key", i)
+		}
+	}
+
+	agreed := writesFromPB(closed[0])
+	for _, writes := range closed[1:] {
+		other := writesFromPB(writes)
+		for c, n := range agreed {
+			if other[c] != n {
+				delete(agreed, c)
+			}
+		}
+	}
+	publish := writesToPB(closed[0].Epoch, agreed)
+	for i, server := range servers {
+		if _, err := (*server).PublishRound(ctx, publication(key, publish)); err != nil {
+			return 0, fmt.Errorf("could not publish round on server %d: %v", i, err)
+		}
+	}
+	return publish.Epoch, nil
+}
+
+// RunRounds flips the rounds of the servers at the end of every epoch of the schedule until stop is closed
+func RunRounds(servers []*pb.BootstrappingClient, key []byte, schedule notify.Schedule, stop <-chan struct{}) {
+	if schedule.Length <= 0 {
+		return
+	}
+	for {
+		next := schedule.Start.Add(time.Duration(schedule.Epoch(time.Now())+1) * schedule.Length)
+		select {
+		case <-stop:
+			return
+		case <-time.After(time.Until(next)):
+		}
+		epoch, err := FlipRound(servers, key)
+		if err != nil {
+			log.Println("round flip failed:", err)
+			continue
+		}
+		log.Println("published epoch", epoch)
+	}
+}
+
+/*
+Agrees on the last published epoch of the servers: a server that missed the publication of the
+last epoch (the flip was interrupted) publishes it with the writes the other servers published.
+Servers that are further behind lost published epochs and cannot be recovered.
+*/
+func recoverRound(ctx context.Context, servers []*pb.BootstrappingClient, key []byte) error {
+	states := make([]*pb.DurableRound, len(servers))
+	ahead := 0
+	for i, server := range servers {
+		var err error
+		if states[i], err = (*server).GetDurableRound(ctx, roundRequest(key, "durable")); err != nil {
+			return fmt.Errorf("could not get durable round of server %d: %v", i, err)
+		}
+		if states[i].Published > states[ahead].Published {
+			ahead = i
+		}
+	}
+	for i, state := range states {
+		if state.Published == states[ahead].Published {
+			continue
+		}
+		if state.Published+1 != states[ahead].Published {
+			return fmt.Errorf("server %d lost the published epochs %d to %d", i, state.Published, states[ahead].Published-1)
+		}
+		closed, err := (*servers[i]).CloseRound(ctx, roundRequest(key, "close"))
+		if err != nil {
+			return fmt.Errorf("could not close round on server %d: %v", i, err)
+		}
+		if closed.Epoch != state.Published {
+			return fmt.Errorf("server %d closed epoch %d instead of %d", i, closed.Epoch, state.Published)
+		}
+		if _, err := (*servers[i]).PublishRound(ctx, publication(key, states[ahead].Last)); err != nil {
+			return fmt.Errorf("could not publish round on server %d: %v", i, err)
+		}
+		log.Println("completed the publication of epoch", state.Published, "on server", i)
+	}
+	return nil
+}
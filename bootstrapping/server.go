@@ -1,34 +1,51 @@
 package bootstrapping
 
 import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
 	"log"
 	"math/rand"
+	"runtime"
 	"sabot/lib/database"
 	"sabot/lib/notify"
 	"sabot/lib/pir"
 	"sabot/lib/util"
 	pb "sabot/proto/bootstrapping"
 	"sync"
+	"time"
 
 	"github.com/dkales/dpf-go/dpf"
 )
 
 type Server struct {
-	*notify.NotifyMatrix
 	*database.ContactDB
-	MultiClient bool
-	NumThreads  int
+	Notifications *notify.EpochMatrix
+	LWEDBs        []*pir.LWEDB // only set up for single-server retrieval
+	MultiClient   bool
+	NumThreads    int
+	// column writes are verified with the other server to have at most RateS ones
+	VerifyColumns bool
+	RateS         int
+	ServerID      int   // 0 or 1
+	Peer          *Peer // only needed with VerifyColumns
+	Reshare       bool  // the notifications are reshared with every published epoch
+	// authenticates the round control of the coordinator (see FlipRound), round control is rejected without it
+	RoundKey []byte
 }
 
-func answerQueriesWorker(db *database.Database, id int, jobs <-chan *pb.Queries, wg *sync.WaitGroup, answers *[]*pb.Answer) {
-	for q := range jobs {
-		var resp *pir.DPFQueryResp
-		var err error
-		for i, query := range q.Queries {
-			resp, err = pir.Process(db.Db, (*dpf.DPFkey)(&query.DpfKey))
-			if err != nil {
-				log.Fatal("error processing query")
-			}
+// returned (wrapped) for malformed client requests, which are rejected before any work is done
+var ErrInvalidRequest = errors.New("invalid request")
+
+// evalWorkers is the number of cores each worker may use to expand the DPFs
+func answerQueriesWorker(db *database.Database, id int, evalWorkers int, jobs <-chan []*dpf.DPFkey, wg *sync.WaitGroup, answers *[]*pb.Answer) {
+	for keys := range jobs {
+		// all queries of a client are answered in a single pass over the database
+		resps, err := pir.ProcessBatchParallel(db.Db, keys, evalWorkers)
+		if err != nil {
+			log.Fatal("error processing query")
+		}
+		for i, resp := range resps {
 			(*answers)[i] = &pb.Answer{Answer: resp.Answer}
 		}
 		log.Printf("Thread %d has done some work!\n", id)
@@ -38,6 +55,15 @@ func answerQueriesWorker(db *database.Database, id int, jobs <-chan *pb.Queries,
 }
 
 func (s *Server) AnswerQueries(in *pb.Queries, queryType database.QueryType) ([]*pb.Answer, error) {
+	// validate all keys before any work is scheduled
+	keys := make([]*dpf.DPFkey, len(in.Queries))
+	for i, q := range in.Queries {
+		var err error
+		if keys[i], err = pir.ParseKey(s.DBs[queryType].Db, q.DpfKey); err != nil {
+			return nil, fmt.Errorf("%w %d: %v", ErrInvalidRequest, i, err)
+		}
+	}
+
 	var wg sync.WaitGroup
 	// one job per client
 	// if singleClient experiment numJubs = 1, else its |Index-PIR DB|
@@ -49,14 +75,24 @@ func (s *Server) AnswerQueries(in *pb.Queries, queryType database.QueryType) ([]
 		numJobs = s.DBs[database.Idx].Db.NumRows
 	}
 	wg.Add(int(numJobs))
-	jobs := make(chan *pb.Queries, numJobs)
+	jobs := make(chan []*dpf.DPFkey, numJobs)
 	answers := make([]*pb.Answer, len(in.Queries))
 
+	// cores not occupied by a worker are used for parallel DPF evaluation
+	busy := s.NumThreads
+	if numJobs < busy {
+		busy = numJobs
+	}
+	evalWorkers := 1
+	if busy > 0 && runtime.NumCPU() > busy {
+		evalWorkers = runtime.NumCPU() / busy
+	}
+
 	for w := 0; w < s.NumThreads; w++ {
-		go answerQueriesWorker(s.DBs[queryType], w, jobs, &wg, &answers)
+		go answerQueriesWorker(s.DBs[queryType], w, evalWorkers, jobs, &wg, &answers)
 	}
 	for j := 0; j < int(numJobs); j++ {
-		jobs <- in
+		jobs <- keys
 	}
 	close(jobs)
 	wg.Wait()
@@ -72,72 +108,419 @@ func (s *Server) AnswerKWQueries(in *pb.Queries) ([]*pb.Answer, error) {
 	return s.AnswerQueries(in, database.Kw)
 }
 
-func (s *Server) SetColumn(in *pb.NotifyRequest) {
+// Builds the LWE matrices and hints for all contact databases, this is expensive and only
+// needs to be done once per database
+func (s *Server) SetupLWE() {
+	s.LWEDBs = make([]*pir.LWEDB, len(s.DBs))
+	for i, db := range s.DBs {
+		s.LWEDBs[i] = pir.NewLWEDB(db.Db, util.RandomPRGKey())
+	}
+}
+
+func answerLWEQueriesWorker(db *pir.LWEDB, id int, jobs <-chan []*pir.LWEQuery, wg *sync.WaitGroup, answers *[]*pb.Answer) {
+	for queries := range jobs {
+		for i, query := range queries {
+			resp, err := pir.ProcessLWE(db, query)
+			if err != nil {
+				log.Fatal("error processing query")
+			}
+			(*answers)[i] = &pb.Answer{Answer: util.Uint32SliceToBytes(resp.Answer)}
+		}
+		log.Printf("Thread %d has done some work!\n", id)
+		wg.Done()
+	}
+}
+
+func (s *Server) AnswerLWEQueries(in *pb.Queries, queryType database.QueryType) ([]*pb.Answer, error) {
+	if s.LWEDBs == nil {
+		return nil, fmt.Errorf("single-server PIR not set up")
+	}
+	db := s.LWEDBs[queryType]
+	// validate all queries before any work is scheduled
+	queries := make([]*pir.LWEQuery, len(in.Queries))
+	for i, q := range in.Queries {
+		if len(q.LweQuery) != 4*db.NumCols {
+			return nil, fmt.Errorf("%w %d: LWE query has length %d, expected %d", ErrInvalidRequest, i, len(q.LweQuery), 4*db.NumCols)
+		}
+		vec, err := util.BytesToUint32Slice(q.LweQuery)
+		if err != nil {
+			return nil, fmt.Errorf("%w %d: %v", ErrInvalidRequest, i, err)
+		}
+		queries[i] = &pir.LWEQuery{Vec: vec}
+	}
+
+	var wg sync.WaitGroup
+	var numJobs int
+	if !s.MultiClient {
+		numJobs = 1
+	} else {
+		numJobs = s.DBs[database.Idx].Db.NumRows
+	}
+	wg.Add(int(numJobs))
+	jobs := make(chan []*pir.LWEQuery, numJobs)
+	answers := make([]*pb.Answer, len(in.Queries))
+
+	for w := 0; w < s.NumThreads; w++ {
+		go answerLWEQueriesWorker(db, w, jobs, &wg, &answers)
+	}
+	for j := 0; j < int(numJobs); j++ {
+		jobs <- queries
+	}
+	close(jobs)
+	wg.Wait()
+
+	return answers, nil
+}
+
+func (s *Server) AnswerLWEIQueries(in *pb.Queries) ([]*pb.Answer, error) {
+	return s.AnswerLWEQueries(in, database.Idx)
+}
+
+func (s *Server) AnswerLWEKWQueries(in *pb.Queries) ([]*pb.Answer, error) {
+	return s.AnswerLWEQueries(in, database.Kw)
+}
+
+// returns the LWE parameters and the hint rows [from, to) for a database
+func (s *Server) GetLWEHint(queryType database.QueryType, from, to uint32) (*pir.LWEParams, []uint32, error) {
+	if s.LWEDBs == nil {
+		return nil, nil, fmt.Errorf("single-server PIR not set up")
+	}
+	if int(queryType) >= len(s.LWEDBs) {
+		return nil, nil, fmt.Errorf("invalid query type: %d", queryType)
+	}
+	db := s.LWEDBs[queryType]
+	hint, err := db.HintRows(int(from), int(to))
+	return db.LWEParams, hint, err
+}
+
+/*
+Writes the column of the request to the current epoch. If the request is for another epoch
+(a flip happened since the client learned the epoch), nothing is written and the returned
+Ack holds the current epoch, so the client can repeat the request on both servers.
+With VerifyColumns the column is sent in parts, which are checked together with the other
+server before anything is written (payloads are not checked).
+The parts can also be sent as DPF keys, which are expanded first.
+If the notifications are authenticated, the request carries the MACs of the column,
+which are stored with the payloads (they are not checked by VerifyColumns either).
+*/
+func (s *Server) SetColumn(in *pb.NotifyRequest) (*pb.Ack, error) {
+	if in.Vec == nil || int(in.Idx) >= s.Notifications.NumRows {
+		return nil, fmt.Errorf("%w: invalid column %d", ErrInvalidRequest, in.Idx)
+	}
+	if len(in.Vec.Payload) != 0 && (s.Notifications.PayloadLen == 0 || len(in.Vec.Payload) != s.Notifications.NumRows*s.Notifications.PayloadLen) {
+		return nil, fmt.Errorf("%w: payload column has length %d", ErrInvalidRequest, len(in.Vec.Payload))
+	}
+	// DPF notifications carry a key per part (and per payload) instead of the shares
+	if len(in.Keys) > 0 {
+		if len(in.Parts) > 0 || (s.RateS > 0 && len(in.Keys) > s.RateS) {
+			return nil, fmt.Errorf("%w: %d keys for at most %d targets", ErrInvalidRequest, len(in.Keys), s.RateS)
+		}
+		var err error
+		if in.Parts, err = notify.ExpandKeys(in.Keys, s.Notifications.NumRows); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+	}
+	if len(in.PayloadKeys) > 0 {
+		if len(in.Vec.Payload) != 0 || s.Notifications.PayloadLen == 0 || (s.RateS > 0 && len(in.PayloadKeys) > s.RateS) {
+			return nil, fmt.Errorf("%w: %d payload keys", ErrInvalidRequest, len(in.PayloadKeys))
+		}
+		var err error
+		if in.Vec.Payload, err = notify.ExpandPayloadKeys(in.PayloadKeys, s.Notifications.NumRows, s.Notifications.PayloadLen); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+	}
+	if s.Notifications.Macs {
+		if len(in.MacKeys) > 0 {
+			if len(in.Vec.Mac) != 0 || (s.RateS > 0 && len(in.MacKeys) > s.RateS) {
+				return nil, fmt.Errorf("%w: %d MAC keys", ErrInvalidRequest, len(in.MacKeys))
+			}
+			var err error
+			if in.Vec.Mac, err = notify.ExpandPayloadKeys(in.MacKeys, s.Notifications.NumRows, notify.MacLen); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+			}
+		}
+		macCol, err := notify.MacColumn(in.MacSeed, in.Vec.Mac, s.Notifications.NumRows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		var payload []byte
+		if len(in.Vec.Payload) != 0 {
+			payload = in.Vec.Payload
+		}
+		in.Vec.Payload = notify.JoinCells(payload, macCol, s.Notifications.NumRows, s.Notifications.PayloadLen)
+	} else if len(in.MacSeed) != 0 || len(in.Vec.Mac) != 0 || len(in.MacKeys) > 0 {
+		return nil, fmt.Errorf("%w: notifications are not authenticated", ErrInvalidRequest)
+	}
+	// the epoch is checked first, the sketches of a write are only exchanged if it can be written
+	if current, err := s.Notifications.CheckWrite(in.Epoch); err != nil {
+		if s.VerifyColumns && s.Peer != nil && len(in.WriteId) > 0 {
+			if err := s.Peer.Abort(in.WriteId, s.ServerID); err != nil {
+				log.Println("could not abort sketch exchange:", err)
+			}
+		}
+		return &pb.Ack{Ok: false, Epoch: current}, nil
+	}
+	if s.VerifyColumns {
+		if len(in.Parts) == 0 || len(in.Parts) > s.RateS || len(in.WriteId) == 0 {
+			return nil, fmt.Errorf("%w: %d parts for at most %d targets", ErrInvalidRequest, len(in.Parts), s.RateS)
+		}
+		triples, err := notify.DecodeTriples(in.Triples, len(in.Parts))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		col, err := s.Peer.VerifyColumn(s.ServerID, s.Notifications.NumRows, in.WriteId, in.Idx, in.Epoch, in.Parts, triples)
+		if errors.Is(err, errSketchAborted) {
+			// the other server is in another epoch, the client repeats the write
+			_, current, _ := s.Notifications.Window()
+			return &pb.Ack{Ok: false, Epoch: current}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		in.Vec.Val = col
+	} else if len(in.Parts) > 0 {
+		col, err := notify.CombineParts(in.Parts, s.Notifications.NumRows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		in.Vec.Val = col
+	}
 
 	var wg sync.WaitGroup
 	var numJobs int
 	if !s.MultiClient {
 		numJobs = 1
 	} else {
-		numJobs = s.NotifyMatrix.NumRows
+		numJobs = s.Notifications.NumRows
 	}
 	wg.Add(int(numJobs))
 	jobs := make(chan *pb.NotifyRequest, numJobs)
+	errs := make(chan error, numJobs)
 
 	for w := 0; w < s.NumThreads; w++ {
-		go setColWorker(s, w, jobs, &wg)
+		go setColWorker(s, w, jobs, &wg, errs)
 	}
 	for j := 0; j < int(numJobs); j++ {
 		jobs <- in
 	}
 	close(jobs)
 	wg.Wait()
+	close(errs)
+	// the epoch can only have been closed in the meantime
+	if err := <-errs; err != nil {
+		_, current, _ := s.Notifications.Window()
+		return &pb.Ack{Ok: false, Epoch: current}, nil
+	}
+	return &pb.Ack{Ok: true, Epoch: in.Epoch}, nil
 }
-func setColWorker(s *Server, id int, jobs <-chan *pb.NotifyRequest, wg *sync.WaitGroup) {
+func setColWorker(s *Server, id int, jobs <-chan *pb.NotifyRequest, wg *sync.WaitGroup, errs chan<- error) {
 	for i := range jobs {
-		// Add column to matrix
-		s.NotifyMatrix.SetColumn(int(i.Idx), i.Vec.Val)
+		// Add column to matrix, a notification without payload removes an old payload of the sender
+		var payload []byte
+		if len(i.Vec.Payload) != 0 {
+			payload = i.Vec.Payload
+		}
+		if err := s.Notifications.SetColumn(i.Epoch, int(i.Idx), i.Vec.Val, payload); err != nil {
+			errs <- err
+		}
 		log.Printf("Thread %d has done some work!\n", id)
 		wg.Done()
 	}
 }
 
-func (s *Server) GetRow(idx uint32) []byte {
+// joins the resharing randomness of both servers for epoch e in the order of the servers
+func (s *Server) reshareNonce(e uint64) ([]byte, error) {
+	if s.Peer == nil {
+		return nil, errors.New("resharing needs a peer")
+	}
+	own, err := s.Notifications.ReshareNonce(e)
+	if err != nil {
+		return nil, err
+	}
+	peer, err := s.Peer.ReshareNonce(e, s.ServerID)
+	if err != nil {
+		return nil, err
+	}
+	if s.ServerID == 0 {
+		return append(own, peer...), nil
+	}
+	return append(peer, own...), nil
+}
+
+// GetReshareNonce returns the resharing randomness of an epoch to the other server
+func (s *Server) GetReshareNonce(in *pb.ReshareNonceRequest) (*pb.ReshareNonce, error) {
+	if s.Peer == nil {
+		return nil, fmt.Errorf("%w: no peer configured", ErrInvalidRequest)
+	}
+	if err := s.Peer.CheckNonceRequest(in, s.ServerID); err != nil {
+		return nil, err
+	}
+	nonce, err := s.Notifications.ReshareNonce(in.Epoch)
+	if err != nil {
+		return nil, err
+	}
+	return s.Peer.AnswerNonce(in.Epoch, s.ServerID, nonce), nil
+}
+
+// ExchangeSketch receives a message of the other server for the verification of a column write
+func (s *Server) ExchangeSketch(in *pb.SketchMessage) error {
+	if s.Peer == nil {
+		return fmt.Errorf("%w: no peer configured", ErrInvalidRequest)
+	}
+	return s.Peer.Receive(in, s.ServerID)
+}
+
+// returns the shares of a row and of its payloads and MACs (nil if disabled), cells holds both
+func (s *Server) rowVector(row []byte, cells []byte, epoch uint64) *pb.Vector {
+	if !s.Notifications.Macs {
+		return &pb.Vector{Val: row, Payload: cells, Epoch: epoch}
+	}
+	payload, mac := notify.SplitCells(cells, s.Notifications.NumRows, s.Notifications.PayloadLen)
+	return &pb.Vector{Val: row, Payload: payload, Mac: mac, Epoch: epoch}
+}
+
+// returns the shares of row idx and of its payloads and MACs (nil if disabled) of the latest readable epoch
+func (s *Server) GetRow(idx uint32) (*pb.Vector, error) {
+	if int(idx) >= s.Notifications.NumRows {
+		return nil, fmt.Errorf("%w: invalid row %d", ErrInvalidRequest, idx)
+	}
 	var wg sync.WaitGroup
 	var numJobs int
 	if !s.MultiClient {
 		numJobs = 1
 
 	} else {
-		numJobs = s.NotifyMatrix.NumRows
+		numJobs = s.Notifications.NumRows
 	}
 	wg.Add(int(numJobs))
 	jobs := make(chan uint32, numJobs)
-	rows := make([][]byte, numJobs)
+	rows := make([]*pb.Vector, s.Notifications.NumRows)
 
 	for w := 0; w < s.NumThreads; w++ {
 		go getRowWorker(s, w, jobs, &wg, &rows)
 	}
-	for j := 0; j < int(numJobs); j++ {
+	// a single client only fetches its own row
+	if !s.MultiClient {
+		jobs <- idx
+	}
+	for j := 0; s.MultiClient && j < int(numJobs); j++ {
 		jobs <- uint32(j)
 	}
 	close(jobs)
 	wg.Wait()
 
-	return rows[idx]
+	return rows[idx], nil
 }
 
-func getRowWorker(s *Server, id int, jobs <-chan uint32, wg *sync.WaitGroup, rows *[][]byte) {
+func getRowWorker(s *Server, id int, jobs <-chan uint32, wg *sync.WaitGroup, rows *[]*pb.Vector) {
 	for i := range jobs {
-		(*rows)[i] = s.NotifyMatrix.GetRow(i)
-		if (*rows)[i] == nil {
-			log.Fatal("row index out of range")
+		row, payload, epoch, err := s.Notifications.GetLatestRow(i)
+		if err != nil {
+			log.Fatal("could not get row: ", err)
 		}
+		(*rows)[i] = s.rowVector(row, payload, epoch)
 		log.Printf("Thread %d has done some work!\n", id)
 		wg.Done()
 	}
 }
 
+/*
+Returns the shares of row idx for all readable epochs in [in.From, in.To] that have not expired.
+All rows are read between the same two publications, as every publication reshares them.
+*/
+func (s *Server) GetRows(in *pb.EpochIndex) (*pb.EpochRows, error) {
+	if int(in.Idx) >= s.Notifications.NumRows || in.From > in.To {
+		return nil, fmt.Errorf("%w: invalid row %d or epochs [%d, %d]", ErrInvalidRequest, in.Idx, in.From, in.To)
+	}
+	for {
+		oldest, current, readable := s.Notifications.Window()
+		out := &pb.EpochRows{From: max(in.From, oldest), Current: current, Published: readable}
+		for e := out.From; e < readable && e <= in.To; e++ {
+			row, payload, err := s.Notifications.GetRow(e, in.Idx)
+			if errors.Is(err, notify.ErrEpochExpired) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			out.Rows = append(out.Rows, s.rowVector(row, payload, 0))
+		}
+		// an epoch was published in the meantime, the rows are read again
+		if _, _, after := s.Notifications.Window(); after == readable {
+			return out, nil
+		}
+	}
+}
+
+// First step of a round flip (see FlipRound), closes the current epoch for writing
+func (s *Server) CloseRound(in *pb.RoundRequest) (*pb.RoundWrites, error) {
+	if err := s.checkRound("close", in.Time, in.Mac, nil); err != nil {
+		return nil, err
+	}
+	e, writes, err := s.Notifications.CloseRound()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	out := writesToPB(e, writes)
+	out.ReshareTag = s.Notifications.ReshareTag()
+	return out, nil
+}
+
+// Second step of a round flip, publishes the closed epoch with the writes both servers agree on
+func (s *Server) PublishRound(in *pb.RoundWrites) error {
+	if len(in.Columns) != len(in.Counts) {
+		return fmt.Errorf("%w: %d columns, %d counts", ErrInvalidRequest, len(in.Columns), len(in.Counts))
+	}
+	if err := s.checkRound("publish", in.Time, in.Mac, in); err != nil {
+		return err
+	}
+	// the key is ratcheted with the randomness of both servers, which only the servers learn
+	var nonce []byte
+	if published, _, _ := s.Notifications.Published(); s.Reshare && in.Epoch >= published {
+		var err error
+		if nonce, err = s.reshareNonce(in.Epoch); err != nil {
+			return err
+		}
+	}
+	if err := s.Notifications.PublishRound(in.Epoch, writesFromPB(in), nonce); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return nil
+}
+
+// Returns the last published epoch, which is on disk if the notifications are persisted.
+// Servers that lost the publication of an epoch are brought up to date by FlipRound.
+func (s *Server) GetDurableRound(in *pb.RoundRequest) (*pb.DurableRound, error) {
+	if err := s.checkRound("durable", in.Time, in.Mac, nil); err != nil {
+		return nil, err
+	}
+	published, current, writes := s.Notifications.Published()
+	var last uint64
+	if published > 0 {
+		last = published - 1
+	}
+	out := &pb.DurableRound{
+		Published:  published,
+		Current:    current,
+		Last:       writesToPB(last, writes),
+		Persistent: s.Notifications.Persistent(),
+	}
+	return out, nil
+}
+
+// round control is only accepted from the coordinator, which holds the round key
+func (s *Server) checkRound(label string, sent int64, mac []byte, writes *pb.RoundWrites) error {
+	if s.RoundKey == nil {
+		return fmt.Errorf("%w: round control needs the flag -roundkey", ErrInvalidRequest)
+	}
+	if !hmac.Equal(mac, roundMac(s.RoundKey, label, sent, writes)) {
+		return fmt.Errorf("%w: round control is not authenticated", ErrInvalidRequest)
+	}
+	// an old request must not close or publish a later round
+	if age := time.Since(time.Unix(sent, 0)); age > util.ROUND_TIMEOUT || age < -util.ROUND_TIMEOUT {
+		return fmt.Errorf("%w: round control was sent %v ago", ErrInvalidRequest, age.Round(time.Second))
+	}
+	return nil
+}
+
 func (s *Server) GetClientSetupValues(cid uint32, numTargets uint32) ([]byte, []byte) {
 
 	ckw := s.DBs[database.Idx].Db.Row(int(cid))[:util.KEY_LENGTH]
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
@@ -12,8 +13,11 @@ import (
 	"sabot/lib/notify"
 	"sabot/lib/util"
 	pb "sabot/proto/bootstrapping"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -22,28 +26,116 @@ const (
 )
 
 var (
-	port = flag.Int("port", 50051, "server port")
+	port     = flag.Int("port", 50051, "server port")
+	logPath  = flag.String("log", "", "file the notifications are logged to and recovered from, empty for no persistence")
+	peerAddr = flag.String("peer", "", "address of the other server, needed to verify column writes")
+	peerKey  = flag.String("peerkey", "", "hex key shared with the other server to verify column writes")
+	// the resharing key is read from a file, so it does not show up in the process list
+	reshareKeyPath = flag.String("resharekey", "", "file with a hex key shared with the other server to reshare notifications, the key is erased from memory once it is used")
+	roundKeyPath   = flag.String("roundkey", "", "file with a hex key shared with the coordinator that flips the rounds (RunRounds)")
 )
 
 type gRPCServer struct {
 	pb.UnimplementedBootstrappingServer
 	*bs.Server
+	peer       *bs.Peer // kept when the server is reset
+	key        []byte   // shared with the other server (-peerkey)
+	reshareKey []byte   // shared with the other server (-resharekey), nil once it is used
+	roundKey   []byte   // shared with the coordinator (-roundkey)
 }
 
 func (s *gRPCServer) SetColumn(ctx context.Context, in *pb.NotifyRequest) (*pb.Ack, error) {
 	if s.Server == nil {
 		return nil, errors.New("server not initialized")
 	}
-	s.Server.SetColumn(in)
-	return &pb.Ack{Ok: true}, nil
+	out, err := s.Server.SetColumn(in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return out, nil
 }
 
 func (s *gRPCServer) GetRow(ctx context.Context, in *pb.Index) (*pb.Vector, error) {
 	if s.Server == nil {
 		return nil, errors.New("server not initialized")
 	}
-	out := s.Server.GetRow(in.Idx)
-	return &pb.Vector{Val: out}, nil
+	out, err := s.Server.GetRow(in.Idx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return out, nil
+}
+
+func (s *gRPCServer) GetRows(ctx context.Context, in *pb.EpochIndex) (*pb.EpochRows, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	out, err := s.Server.GetRows(in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return out, nil
+}
+
+func (s *gRPCServer) CloseRound(ctx context.Context, in *pb.RoundRequest) (*pb.RoundWrites, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	out, err := s.Server.CloseRound(in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return out, nil
+}
+
+func (s *gRPCServer) GetDurableRound(ctx context.Context, in *pb.RoundRequest) (*pb.DurableRound, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	out, err := s.Server.GetDurableRound(in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return out, nil
+}
+
+func (s *gRPCServer) PublishRound(ctx context.Context, in *pb.RoundWrites) (*pb.Ack, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	if err := s.Server.PublishRound(in); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Ack{Ok: true}, nil
+}
+
+func (s *gRPCServer) ExchangeSketch(ctx context.Context, in *pb.SketchMessage) (*pb.Ack, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	if err := s.Server.ExchangeSketch(in); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Ack{Ok: true}, nil
+}
+
+func (s *gRPCServer) GetReshareNonce(ctx context.Context, in *pb.ReshareNonceRequest) (*pb.ReshareNonce, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	out, err := s.Server.GetReshareNonce(in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return out, nil
+}
+
+// malformed client input is reported as InvalidArgument instead of an unknown error
+func grpcError(err error) error {
+	if errors.Is(err, bs.ErrInvalidRequest) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
 }
 
 func (s *gRPCServer) MakeIQueries(ctx context.Context, in *pb.Queries) (*pb.Answers, error) {
@@ -51,8 +143,10 @@ func (s *gRPCServer) MakeIQueries(ctx context.Context, in *pb.Queries) (*pb.Answ
 		return nil, errors.New("server not initialized")
 	}
 	out, err := s.Server.AnswerIQueries(in)
-
-	return &pb.Answers{Answers: out}, err
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Answers{Answers: out}, nil
 }
 
 func (s *gRPCServer) MakeKWQueries(ctx context.Context, in *pb.Queries) (*pb.Answers, error) {
@@ -60,8 +154,56 @@ func (s *gRPCServer) MakeKWQueries(ctx context.Context, in *pb.Queries) (*pb.Ans
 		return nil, errors.New("server not initialized")
 	}
 	out, err := s.Server.AnswerKWQueries(in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Answers{Answers: out}, nil
+}
 
-	return &pb.Answers{Answers: out}, err
+func (s *gRPCServer) MakeLWEIQueries(ctx context.Context, in *pb.Queries) (*pb.Answers, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	out, err := s.Server.AnswerLWEIQueries(in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Answers{Answers: out}, nil
+}
+
+func (s *gRPCServer) MakeLWEKWQueries(ctx context.Context, in *pb.Queries) (*pb.Answers, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	out, err := s.Server.AnswerLWEKWQueries(in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Answers{Answers: out}, nil
+}
+
+func (s *gRPCServer) GetLWEHint(ctx context.Context, in *pb.HintRequest) (*pb.LWEHint, error) {
+	if s.Server == nil {
+		return nil, errors.New("server not initialized")
+	}
+	if len(in.QueryType) != 4 {
+		return nil, errors.New("invalid query type")
+	}
+	pp, hint, err := s.Server.GetLWEHint(database.QueryType(util.ByteSliceToUint32(in.QueryType)), in.From, in.To)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LWEHint{
+		Params: &pb.LWEParams{
+			N:          pp.N,
+			NumRecords: uint32(pp.NumRecords),
+			RowLen:     uint32(pp.RowLen),
+			NumCols:    uint32(pp.NumCols),
+			M:          uint32(pp.M),
+			Seed:       pp.Seed[:],
+		},
+		Hint: util.Uint32SliceToBytes(hint),
+	}, nil
 }
 
 /*
@@ -72,22 +214,77 @@ Server returns the protocol's parameters to the client
 */
 func (s *gRPCServer) SetupExperiment(ctx context.Context, in *pb.Config) (*pb.ParamResp, error) {
 	if in.ResetServer {
+		// the log is reopened and replayed below
+		if s.Server != nil && s.Notifications != nil {
+			s.Notifications.Close()
+		}
 		s.Server = &bs.Server{}
 		// Set DB Type and read DB(s) from disk
 		s.ContactDB = &database.ContactDB{DBType: database.DBType(util.ByteSliceToUint32(in.DbType))}
 		log.Println("reset server: init from File(s):", in.Dbfile, "dbtype: ", s.ContactDB.DBType)
 		s.ContactDB.FromDisk(localTestPrefix + in.Dbfile)
 
-		// Set size of notification matrix to size of index database
-		s.NotifyMatrix = notify.NewMatrix(int(s.DBs[database.Idx].Db.NumRows))
-
 	} else if s.Server == nil {
 		log.Fatalln("server not initialized!")
 	}
+	// both servers get the same epoch schedule from the client, so they keep the same epochs
+	schedule := notify.Schedule{
+		Start:     time.Unix(in.EpochStart, 0),
+		Length:    time.Duration(in.EpochLength) * time.Second,
+		Retention: uint64(in.EpochRetention),
+	}
+	if in.Reshare && (in.EpochLength == 0 || s.peer == nil) {
+		return nil, errors.New("resharing needs epochs and the flags -peer and -peerkey")
+	}
+	// Set size of notification matrix to size of index database,
+	// notifications are kept unless the payload length, the schedule, resharing or authentication change,
+	// notifications are authenticated with MACs if the index database is
+	auth := s.DBs[database.Idx].Pp.Auth
+	if in.ResetServer || s.Notifications == nil || s.Notifications.PayloadLen != int(in.PayloadLen) || s.Notifications.Macs != auth ||
+		!s.Notifications.Schedule.Start.Equal(schedule.Start) || s.Notifications.Schedule.Length != schedule.Length ||
+		s.Notifications.Schedule.Retention != schedule.Retention || s.Reshare != in.Reshare {
+		if s.Notifications != nil {
+			s.Notifications.Close()
+		}
+		s.Notifications = notify.NewEpochMatrix(int(s.DBs[database.Idx].Db.NumRows), int(in.PayloadLen), schedule)
+		s.Notifications.Macs = auth
+		// the key is only used for one matrix, a logged matrix continues with the ratcheted key of its log
+		if in.Reshare && s.reshareKey != nil {
+			s.Notifications.EnableResharing(s.reshareKey)
+			clear(s.reshareKey)
+			s.reshareKey = nil
+		}
+		s.Reshare = in.Reshare
+		// notifications that survived a restart or reset are recovered from the log
+		if *logPath != "" {
+			if err := s.Notifications.Persist(*logPath); err != nil {
+				log.Fatalln("could not recover notifications:", err)
+			}
+			published, current, _ := s.Notifications.Published()
+			log.Println("recovered notifications from", *logPath, "published epochs:", published, "current epoch:", current)
+		}
+		if in.Reshare && s.Notifications.ReshareTag() == nil {
+			s.Reshare = false
+			return nil, errors.New("resharing needs the flag -resharekey, the key can only be used once unless the notifications are logged")
+		}
+	}
+	// LWE hints are only computed if single-server retrieval is requested
+	if in.SingleServer && s.LWEDBs == nil {
+		log.Println("setting up single-server PIR")
+		s.Server.SetupLWE()
+	}
 	// Set all other server config parameters
 
 	s.MultiClient = in.MultiClient
 	s.NumThreads = int(in.NumThreads)
+	s.ServerID = int(in.ServerID)
+	s.RateS = int(in.NumTargets)
+	s.VerifyColumns = in.VerifyColumns
+	s.Peer = s.peer
+	s.RoundKey = s.roundKey
+	if s.VerifyColumns && s.Peer == nil {
+		return nil, errors.New("column verification needs the flags -peer and -peerkey")
+	}
 
 	// For Benchmarking Purposes
 	// Return the clients KW and some existing target keywords to the client
@@ -140,6 +337,49 @@ func main() {
 	grpcServer := &gRPCServer{}
 	pb.RegisterBootstrappingServer(s, grpcServer)
 
+	if *peerKey != "" {
+		grpcServer.key, err = hex.DecodeString(*peerKey)
+		if err != nil || len(grpcServer.key) < 16 {
+			log.Fatal("-peerkey has to be a hex key of at least 16 bytes")
+		}
+	}
+	if *reshareKeyPath != "" {
+		if grpcServer.reshareKey, err = util.ReadKeyFile(*reshareKeyPath); err != nil {
+			log.Fatal("could not read -resharekey: ", err)
+		}
+	}
+	if *roundKeyPath != "" {
+		if grpcServer.roundKey, err = util.ReadKeyFile(*roundKeyPath); err != nil {
+			log.Fatal("could not read -roundkey: ", err)
+		}
+	}
+	// the other server is contacted like a client would
+	if *peerAddr != "" {
+		if grpcServer.key == nil {
+			log.Fatal("-peer needs -peerkey")
+		}
+		peerCreds, err := util.LoadTLSCred(localDebugPrefix+util.CERT_C_PATH_PRE, localDebugPrefix+util.CERT_CA_PATH, true)
+		if err != nil {
+			log.Fatal("cannot load TLS credentials: ", err)
+		}
+		conn, err := grpc.Dial(*peerAddr, grpc.WithTransportCredentials(peerCreds))
+		if err != nil {
+			log.Fatalf("did not connect to peer: %v", err)
+		}
+		defer conn.Close()
+		peer := pb.NewBootstrappingClient(conn)
+		grpcServer.peer = bs.NewPeer(grpcServer.key, func(m *pb.SketchMessage) error {
+			ctx, cancel := context.WithTimeout(context.Background(), util.SKETCH_TIMEOUT)
+			defer cancel()
+			_, err := peer.ExchangeSketch(ctx, m)
+			return err
+		}, func(m *pb.ReshareNonceRequest) (*pb.ReshareNonce, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), util.SKETCH_TIMEOUT)
+			defer cancel()
+			return peer.GetReshareNonce(ctx, m)
+		})
+	}
+
 	log.Printf("server listening at %v", lis.Addr())
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
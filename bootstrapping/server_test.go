@@ -2,11 +2,21 @@ package bootstrapping
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"log"
+	"math"
 	"sabot/lib/database"
 	"sabot/lib/notify"
+	"sabot/lib/pir"
 	"sabot/lib/util"
+	pb "sabot/proto/bootstrapping"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/dkales/dpf-go/dpf"
+	"google.golang.org/grpc"
 )
 
 func TestServer(t *testing.T) {
@@ -27,7 +37,7 @@ func TestServer(t *testing.T) {
 	inputs := database.GetTestData(numInputs, uint(keylen), uint(valuelen), 42)
 
 	s.ContactDB.Setup(inputs, auth)
-	s.NotifyMatrix = notify.NewMatrix(s.DBs[database.Idx].Db.NumRows)
+	s.Notifications = notify.NewEpochMatrix(s.DBs[database.Idx].Db.NumRows, 0, notify.Schedule{})
 
 	numTargets := 10
 	// Test get Client Value functionality for all elements
@@ -40,3 +50,578 @@ func TestServer(t *testing.T) {
 		}
 	}
 }
+
+func TestServerLWE(t *testing.T) {
+	s := Server{
+		ContactDB:   &database.ContactDB{DBType: database.TwoDB},
+		MultiClient: false,
+		NumThreads:  1,
+	}
+	inputs := database.GetTestData(100, uint(util.KEY_LENGTH), uint(util.VAL_LENGTH), 42)
+	s.ContactDB.Setup(inputs, false)
+	s.SetupLWE()
+
+	for _, queryType := range []database.QueryType{database.Idx, database.Kw} {
+		pp, hint, err := s.GetLWEHint(queryType, 0, uint32(s.LWEDBs[queryType].M))
+		if err != nil {
+			t.Fatal("failed to get hint:", err)
+		}
+		client := pir.InitLWEClient(pp, hint)
+
+		idx := 7
+		query, reconstructFunc := client.Query(idx)
+		answers, err := s.AnswerLWEQueries(&pb.Queries{Queries: []*pb.Query{{LweQuery: util.Uint32SliceToBytes(query.Vec)}}}, queryType)
+		if err != nil {
+			t.Fatal("failed to answer query:", err)
+		}
+		answer, err := util.BytesToUint32Slice(answers[0].Answer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		row, err := reconstructFunc([]interface{}{&pir.LWEQueryResp{Answer: answer}})
+		if err != nil {
+			t.Fatal("failed to reconstruct:", err)
+		}
+		if !bytes.Equal(row, s.DBs[queryType].Db.Row(idx)) {
+			t.Fatal("retrieved row does not match for db", queryType)
+		}
+	}
+
+	// malformed queries are rejected
+	if _, err := s.AnswerLWEIQueries(&pb.Queries{Queries: []*pb.Query{{LweQuery: []byte{1, 2, 3}}}}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for malformed query")
+	}
+}
+
+func TestServerDPF(t *testing.T) {
+	s := Server{
+		ContactDB:   &database.ContactDB{DBType: database.TwoDB},
+		MultiClient: false,
+		NumThreads:  1,
+	}
+	inputs := database.GetTestData(100, uint(util.KEY_LENGTH), uint(util.VAL_LENGTH), 42)
+	s.ContactDB.Setup(inputs, false)
+
+	db := s.DBs[database.Idx].Db
+	client := pir.InitPIRClient(db.Params(), pir.RandSource())
+	idx := 42
+	keys, _ := client.Query(idx)
+	answers := make([][]byte, 2)
+	for k := range answers {
+		resp, err := s.AnswerIQueries(&pb.Queries{Queries: []*pb.Query{{DpfKey: client.EncodeKey(keys[k])}}})
+		if err != nil {
+			t.Fatal("failed to answer query:", err)
+		}
+		answers[k] = resp[0].Answer
+	}
+	row, _ := client.Reconstruct(answers)
+	if !bytes.Equal(row, db.Row(idx)) {
+		t.Fatal("retrieved row does not match")
+	}
+
+	// malformed keys are rejected instead of crashing the server
+	valid := client.EncodeKey(keys[0])
+	for _, key := range [][]byte{nil, valid[:len(valid)-1], append(valid, 0), append([]byte{dpf.KeyVersion, 30}, *keys[0]...), *keys[0]} {
+		if _, err := s.AnswerIQueries(&pb.Queries{Queries: []*pb.Query{{DpfKey: key}}}); !errors.Is(err, ErrInvalidRequest) {
+			t.Fatalf("expected error for malformed key, got %v", err)
+		}
+	}
+}
+
+func TestServerPayloads(t *testing.T) {
+	s := Server{MultiClient: false, NumThreads: 1}
+	size := 50
+	payloadLen := 32
+	s.Notifications = notify.NewEpochMatrix(size, payloadLen, notify.Schedule{})
+
+	sender, receiver := uint32(3), uint32(17)
+	payload := bytes.Repeat([]byte{7}, payloadLen)
+	col := notify.CreateVector([]uint32{receiver}, uint32(size))
+	payloadCol := notify.CreatePayloadColumn([]uint32{receiver}, [][]byte{payload}, uint32(size), payloadLen)
+	if ack, err := s.SetColumn(&pb.NotifyRequest{Idx: sender, Vec: &pb.Vector{Val: col, Payload: payloadCol}}); err != nil || !ack.Ok {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(notify.ReadPayloads(getRow(t, &s, receiver).Payload, []uint32{sender}, payloadLen)[0], payload) {
+		t.Fatal("wrong payload")
+	}
+
+	// a notification without payload clears the old payload
+	if _, err := s.SetColumn(&pb.NotifyRequest{Idx: sender, Vec: &pb.Vector{Val: col}}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(notify.ReadPayloads(getRow(t, &s, receiver).Payload, []uint32{sender}, payloadLen)[0], make([]byte, payloadLen)) {
+		t.Fatal("payload not cleared")
+	}
+
+	for _, req := range []*pb.NotifyRequest{
+		{Idx: sender, Vec: &pb.Vector{Val: col, Payload: payloadCol[1:]}},
+		{Idx: uint32(size), Vec: &pb.Vector{Val: col}},
+		{Idx: sender},
+	} {
+		if _, err := s.SetColumn(req); !errors.Is(err, ErrInvalidRequest) {
+			t.Fatalf("expected error for malformed request, got %v", err)
+		}
+	}
+	if _, err := s.GetRow(uint32(size)); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected error for invalid row, got %v", err)
+	}
+}
+
+// returns the latest row of a server, which has to be valid
+func getRow(t *testing.T, s *Server, idx uint32) *pb.Vector {
+	row, err := s.GetRow(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return row
+}
+
+func TestServerMacs(t *testing.T) {
+	s := Server{MultiClient: false, NumThreads: 1}
+	size := 50
+	payloadLen := 4
+	s.Notifications = notify.NewEpochMatrix(size, payloadLen, notify.Schedule{})
+	s.Notifications.Macs = true
+
+	// a single server holds the whole column, so its row has to pass the check on its own
+	sender, receiver := uint32(3), uint32(17)
+	col := notify.CreateVector([]uint32{receiver}, uint32(size))
+	payloadCol := notify.CreatePayloadColumn([]uint32{receiver}, [][]byte{{1, 2}}, uint32(size), payloadLen)
+	seeds, tags := notify.GenMacs(col, payloadCol, uint32(size), payloadLen, 1)
+	if ack, err := s.SetColumn(&pb.NotifyRequest{Idx: sender, Vec: &pb.Vector{Val: col, Payload: payloadCol, Mac: tags[0]}, MacSeed: seeds[0]}); err != nil || !ack.Ok {
+		t.Fatal(err)
+	}
+	row := getRow(t, &s, receiver)
+	if _, err := notify.VerifyRow(row.Val, row.Payload, row.Mac, payloadLen); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(notify.ReadVector(row.Val), []uint32{sender}) || notify.ReadPayloads(row.Payload, []uint32{sender}, payloadLen)[0][1] != 2 {
+		t.Fatal("wrong row", notify.ReadVector(row.Val))
+	}
+
+	for _, req := range []*pb.NotifyRequest{
+		{Idx: sender, Vec: &pb.Vector{Val: col}},
+		{Idx: sender, Vec: &pb.Vector{Val: col, Mac: tags[0][1:]}, MacSeed: seeds[0]},
+	} {
+		if _, err := s.SetColumn(req); !errors.Is(err, ErrInvalidRequest) {
+			t.Fatalf("expected error for request without MACs, got %v", err)
+		}
+	}
+	s.Notifications = notify.NewEpochMatrix(size, payloadLen, notify.Schedule{})
+	if _, err := s.SetColumn(&pb.NotifyRequest{Idx: sender, Vec: &pb.Vector{Val: col, Mac: tags[0]}, MacSeed: seeds[0]}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected error for MACs of unauthenticated notifications, got %v", err)
+	}
+}
+
+// key of the coordinator that flips the rounds in the tests
+var testRoundKey = []byte("fedcba9876543210")
+
+// calls the round flip and notification RPCs of a server directly
+type localServer struct {
+	pb.BootstrappingClient
+	s      *Server
+	tamper func(*pb.Vector) // changes the rows the server returns, if set
+}
+
+func (l localServer) SetColumn(ctx context.Context, in *pb.NotifyRequest, opts ...grpc.CallOption) (*pb.Ack, error) {
+	return l.s.SetColumn(in)
+}
+
+func (l localServer) GetRow(ctx context.Context, in *pb.Index, opts ...grpc.CallOption) (*pb.Vector, error) {
+	out, err := l.s.GetRow(in.Idx)
+	if err == nil && l.tamper != nil {
+		l.tamper(out)
+	}
+	return out, err
+}
+
+func (l localServer) GetRows(ctx context.Context, in *pb.EpochIndex, opts ...grpc.CallOption) (*pb.EpochRows, error) {
+	out, err := l.s.GetRows(in)
+	if err == nil && l.tamper != nil {
+		for _, row := range out.Rows {
+			l.tamper(row)
+		}
+	}
+	return out, err
+}
+
+func (l localServer) CloseRound(ctx context.Context, in *pb.RoundRequest, opts ...grpc.CallOption) (*pb.RoundWrites, error) {
+	return l.s.CloseRound(in)
+}
+
+func (l localServer) GetDurableRound(ctx context.Context, in *pb.RoundRequest, opts ...grpc.CallOption) (*pb.DurableRound, error) {
+	return l.s.GetDurableRound(in)
+}
+
+func (l localServer) PublishRound(ctx context.Context, in *pb.RoundWrites, opts ...grpc.CallOption) (*pb.Ack, error) {
+	return &pb.Ack{Ok: true}, l.s.PublishRound(in)
+}
+
+func TestServerEpochs(t *testing.T) {
+	size := 50
+	schedule := notify.Schedule{Start: time.Now(), Length: time.Hour, Retention: 2}
+	servers := make([]*Server, 2)
+	clients := make([]*pb.BootstrappingClient, 2)
+	for i := range servers {
+		servers[i] = &Server{MultiClient: false, NumThreads: 1, RoundKey: testRoundKey, Notifications: notify.NewEpochMatrix(size, 0, schedule)}
+		var client pb.BootstrappingClient = localServer{s: servers[i]}
+		clients[i] = &client
+	}
+	notifyAll := func(sender uint32, receivers []uint32, epoch uint64, to []*Server) {
+		shares := notify.GenShares(notify.CreateVector(receivers, uint32(size)), 2)
+		for i, s := range to {
+			if ack, err := s.SetColumn(&pb.NotifyRequest{Idx: sender, Vec: &pb.Vector{Val: shares[i]}, Epoch: epoch}); err != nil || !ack.Ok {
+				t.Fatal("write rejected", err)
+			}
+		}
+	}
+	readAll := func(receiver uint32, from uint64) ([][]byte, *pb.EpochRows) {
+		res := make([]*pb.EpochRows, 2)
+		for i, s := range servers {
+			var err error
+			if res[i], err = s.GetRows(&pb.EpochIndex{Idx: receiver, From: from, To: math.MaxUint64}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		rows := make([][]byte, len(res[0].Rows))
+		for e := range rows {
+			rows[e] = notify.CombineShares([][]byte{res[0].Rows[e].Val, res[1].Rows[e].Val})
+		}
+		return rows, res[0]
+	}
+
+	receiver := uint32(17)
+	notifyAll(3, []uint32{receiver}, 0, servers)
+	// the second sender's request crosses the flip and only reaches the first server
+	notifyAll(4, []uint32{receiver}, 0, servers[:1])
+	if rows, _ := readAll(receiver, 0); len(rows) != 0 {
+		t.Fatal("unpublished epoch read")
+	}
+	if epoch, err := FlipRound(clients, testRoundKey); err != nil || epoch != 0 {
+		t.Fatal("flip failed", err)
+	}
+	if ack, _ := servers[1].SetColumn(&pb.NotifyRequest{Idx: 4, Vec: &pb.Vector{Val: make([]byte, (size+7)/8)}, Epoch: 0}); ack.Ok || ack.Epoch != 1 {
+		t.Fatal("write to closed epoch accepted")
+	}
+	notifyAll(4, []uint32{receiver}, 1, servers)
+	if _, err := FlipRound(clients, testRoundKey); err != nil {
+		t.Fatal("flip failed", err)
+	}
+
+	var mb notify.Mailbox
+	rows, res := readAll(receiver, 0)
+	if res.From != 0 || res.Current != 2 || len(rows) != 2 {
+		t.Fatal("wrong epoch window", res.From, res.Current, len(rows))
+	}
+	if senders, epochs := mb.Read(res.From, rows, res.Current); !slices.Equal(senders, []uint32{3, 4}) || !slices.Equal(epochs, []uint64{0, 1}) {
+		t.Fatal("wrong senders", senders, epochs)
+	}
+	// GetRow only returns the latest published epoch
+	latest := []*pb.Vector{getRow(t, servers[0], receiver), getRow(t, servers[1], receiver)}
+	if latest[0].Epoch != 1 || !slices.Equal(notify.ReadVector(notify.CombineShares([][]byte{latest[0].Val, latest[1].Val})), []uint32{4}) {
+		t.Fatal("wrong row of latest epoch")
+	}
+	if _, err := servers[0].GetRows(&pb.EpochIndex{Idx: receiver, From: 4, To: 3}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for invalid window")
+	}
+
+	// a flip interrupted after the publication on the first server is completed
+	notifyAll(5, []uint32{receiver}, 2, servers)
+	closed, _ := servers[0].CloseRound(roundRequest(testRoundKey, "close"))
+	servers[0].PublishRound(publication(testRoundKey, closed))
+	if epoch, err := FlipRound(clients, testRoundKey); err != nil || epoch != 3 {
+		t.Fatal("flip failed", epoch, err)
+	}
+	rows, res = readAll(receiver, 2)
+	if senders, _ := mb.Read(res.From, rows, res.Current); len(rows) != 2 || !slices.Equal(senders, []uint32{5}) {
+		t.Fatal("wrong senders after recovery", senders)
+	}
+
+	// only the coordinator controls the rounds, and it can only drop writes
+	if _, err := FlipRound(clients, []byte("0123456789abcdef")); err == nil {
+		t.Fatal("expected error for round control without the round key")
+	}
+	old := &pb.RoundRequest{Time: time.Now().Add(-time.Hour).Unix()}
+	old.Mac = roundMac(testRoundKey, "close", old.Time, nil)
+	if _, err := servers[0].CloseRound(old); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for old round control, got", err)
+	}
+	if _, err := servers[0].GetDurableRound(roundRequest(testRoundKey, "close")); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for round control of another RPC, got", err)
+	}
+	notifyAll(6, []uint32{receiver}, 4, servers)
+	closed, _ = servers[0].CloseRound(roundRequest(testRoundKey, "close"))
+	forged := publication(testRoundKey, writesToPB(closed.Epoch, map[uint32]uint32{6: 2}))
+	if err := servers[0].PublishRound(forged); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for writes the epoch does not have, got", err)
+	}
+	forged = publication(testRoundKey, closed)
+	forged.Columns = nil
+	forged.Counts = nil
+	if err := servers[0].PublishRound(forged); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for changed writes, got", err)
+	}
+	if epoch, err := FlipRound(clients, testRoundKey); err != nil || epoch != 4 {
+		t.Fatal("flip failed", epoch, err)
+	}
+	rows, res = readAll(receiver, 4)
+	if senders, _ := mb.Read(res.From, rows, res.Current); !slices.Equal(senders, []uint32{6}) {
+		t.Fatal("wrong senders after rejected round control", senders)
+	}
+
+	// servers that lost published epochs are not flipped
+	servers[1].Notifications = notify.NewEpochMatrix(size, 0, schedule)
+	if _, err := FlipRound(clients, testRoundKey); err == nil {
+		t.Fatal("expected error for servers in different epochs")
+	}
+}
+
+// lets the two servers call each other directly
+func connectPeers(servers []*Server, key []byte) {
+	for i := range servers {
+		other := servers[1-i]
+		servers[i].Peer = NewPeer(key, func(m *pb.SketchMessage) error { return other.ExchangeSketch(m) },
+			func(m *pb.ReshareNonceRequest) (*pb.ReshareNonce, error) { return other.GetReshareNonce(m) })
+	}
+}
+
+func TestServerVerifyColumns(t *testing.T) {
+	size := 50
+	rateS := 3
+	key := []byte("0123456789abcdef")
+	servers := make([]*Server, 2)
+	for i := range servers {
+		servers[i] = &Server{MultiClient: false, NumThreads: 1, VerifyColumns: true, RateS: rateS, ServerID: i,
+			Notifications: notify.NewEpochMatrix(size, 0, notify.Schedule{})}
+	}
+	connectPeers(servers, key)
+	// changes the request to server i before it is sent, if set
+	var tamper func(i int, req *pb.NotifyRequest)
+	// writes the parts to both servers at the same time, as they wait for each other
+	write := func(sender uint32, parts [][]byte) [2]error {
+		reqs := [2]*pb.NotifyRequest{}
+		for i := range reqs {
+			reqs[i] = &pb.NotifyRequest{Idx: sender, Vec: &pb.Vector{}, WriteId: []byte{byte(sender), 1, 2, 3}}
+		}
+		for _, part := range parts {
+			for i, share := range notify.GenShares(part, 2) {
+				reqs[i].Parts = append(reqs[i].Parts, share)
+			}
+		}
+		for i, triples := range notify.GenTriples(len(parts)) {
+			reqs[i].Triples = notify.EncodeTriples(triples)
+			if tamper != nil {
+				tamper(i, reqs[i])
+			}
+		}
+		var errs [2]error
+		done := make(chan struct{})
+		for i, s := range servers {
+			go func(i int, s *Server) {
+				var ack *pb.Ack
+				if ack, errs[i] = s.SetColumn(reqs[i]); errs[i] == nil && !ack.Ok {
+					errs[i] = errors.New("write rejected")
+				}
+				done <- struct{}{}
+			}(i, s)
+		}
+		<-done
+		<-done
+		return errs
+	}
+	notified := func(receiver, sender uint32) bool {
+		row := notify.CombineShares([][]byte{getRow(t, servers[0], receiver).Val, getRow(t, servers[1], receiver).Val})
+		return slices.Contains(notify.ReadVector(row), sender)
+	}
+
+	if errs := write(3, notify.SplitColumn([]uint32{17, 20}, uint32(size), rateS)); errs[0] != nil || errs[1] != nil {
+		t.Fatal("valid column rejected", errs)
+	}
+	if !notified(17, 3) || !notified(20, 3) || notified(21, 3) {
+		t.Fatal("wrong column written")
+	}
+
+	// a part with more than one target is rejected by both servers
+	parts := notify.SplitColumn([]uint32{17}, uint32(size), rateS)
+	parts[2] = notify.CreateVector([]uint32{30, 31}, uint32(size))
+	if errs := write(4, parts); !errors.Is(errs[0], ErrInvalidRequest) || !errors.Is(errs[1], ErrInvalidRequest) {
+		t.Fatal("expected error for invalid column", errs)
+	}
+	if notified(17, 4) || notified(30, 4) {
+		t.Fatal("invalid column written")
+	}
+
+	// more parts than the rate
+	if _, err := servers[0].SetColumn(&pb.NotifyRequest{Idx: 5, Vec: &pb.Vector{}, WriteId: []byte{5},
+		Parts: notify.SplitColumn(nil, uint32(size), rateS+1), Triples: notify.EncodeTriples(notify.GenTriples(rateS + 1)[0])}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for too many parts", err)
+	}
+	// sketch messages have to be authenticated by the other server
+	if err := servers[0].ExchangeSketch(&pb.SketchMessage{WriteId: []byte{6}, Round: 1, ServerID: 1, Values: []uint64{1, 2}}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for unauthenticated message", err)
+	}
+
+	// a write for another epoch is rejected without verifying it, the other server stops waiting
+	tamper = func(i int, req *pb.NotifyRequest) {
+		if i == 0 {
+			req.Epoch = 1
+		}
+	}
+	if errs := write(6, notify.SplitColumn([]uint32{17}, uint32(size), rateS)); errs[0] == nil || errs[1] == nil ||
+		errors.Is(errs[0], ErrInvalidRequest) || errors.Is(errs[1], ErrInvalidRequest) {
+		t.Fatal("expected both servers to reject the write of an old epoch", errs)
+	}
+	if notified(17, 6) {
+		t.Fatal("write of an old epoch written")
+	}
+
+	// the verification of a write only holds for its column, both servers reject a write to different columns
+	tamper = func(i int, req *pb.NotifyRequest) { req.Idx += uint32(i) }
+	if errs := write(7, notify.SplitColumn([]uint32{17}, uint32(size), rateS)); !errors.Is(errs[0], ErrInvalidRequest) || !errors.Is(errs[1], ErrInvalidRequest) {
+		t.Fatal("expected error for writes to different columns", errs)
+	}
+	if notified(17, 7) || notified(17, 8) {
+		t.Fatal("write to different columns written")
+	}
+}
+
+func TestServerReshare(t *testing.T) {
+	size := 50
+	schedule := notify.Schedule{Start: time.Now(), Length: time.Hour, Retention: 2}
+	servers := make([]*Server, 2)
+	clients := make([]*pb.BootstrappingClient, 2)
+	for i := range servers {
+		servers[i] = &Server{MultiClient: false, NumThreads: 1, Reshare: true, ServerID: i, RoundKey: testRoundKey, Notifications: notify.NewEpochMatrix(size, 0, schedule)}
+		var client pb.BootstrappingClient = localServer{s: servers[i]}
+		clients[i] = &client
+	}
+	connectPeers(servers, []byte("0123456789abcdef"))
+	// only the other server gets the resharing randomness
+	if _, err := servers[0].GetReshareNonce(&pb.ReshareNonceRequest{Epoch: 0, ServerID: 1}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected error for unauthenticated request", err)
+	}
+	servers[0].Notifications.EnableResharing([]byte("0123456789abcdef"))
+	// a server without the key would reshare differently
+	if _, err := FlipRound(clients, testRoundKey); err == nil {
+		t.Fatal("expected error for servers with different resharing keys")
+	}
+	servers[1].Notifications.EnableResharing([]byte("0123456789abcdef"))
+
+	receiver := uint32(17)
+	shares := notify.GenShares(notify.CreateVector([]uint32{receiver}, uint32(size)), 2)
+	for i, s := range servers {
+		if ack, err := s.SetColumn(&pb.NotifyRequest{Idx: 3, Vec: &pb.Vector{Val: shares[i]}, Epoch: 1}); err != nil || !ack.Ok {
+			t.Fatal("write rejected", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := FlipRound(clients, testRoundKey); err != nil {
+			t.Fatal("flip failed", err)
+		}
+	}
+	rows := [][]byte{nil, nil}
+	for i, s := range servers {
+		res, err := s.GetRows(&pb.EpochIndex{Idx: receiver, From: 1, To: 1})
+		if err != nil || len(res.Rows) != 1 {
+			t.Fatal("could not get row", err)
+		}
+		rows[i] = res.Rows[0].Val
+	}
+	if bytes.Equal(rows[0], shares[0]) {
+		t.Fatal("shares not reshared")
+	}
+	if !slices.Equal(notify.ReadVector(notify.CombineShares(rows)), []uint32{3}) {
+		t.Fatal("resharing changed the notification")
+	}
+
+	// the rows of a server that published one more epoch are reshared once more, which its response shows
+	closed, _ := servers[0].CloseRound(roundRequest(testRoundKey, "close"))
+	servers[1].CloseRound(roundRequest(testRoundKey, "close"))
+	if err := servers[0].PublishRound(publication(testRoundKey, closed)); err != nil {
+		t.Fatal(err)
+	}
+	var published [2]uint64
+	for i, s := range servers {
+		res, err := s.GetRows(&pb.EpochIndex{Idx: receiver, From: 1, To: 1})
+		if err != nil {
+			t.Fatal("could not get row", err)
+		}
+		published[i] = res.Published
+	}
+	if published[0] != published[1]+1 {
+		t.Fatal("wrong published epochs", published)
+	}
+
+	// the interrupted flip is completed with the randomness the first server published with,
+	// which the second server gets from the first one
+	if _, err := FlipRound(clients, testRoundKey); err != nil {
+		t.Fatal("flip failed", err)
+	}
+	// the notification has expired, the epochs left are empty
+	all := make([]*pb.EpochRows, 2)
+	for i, s := range servers {
+		var err error
+		if all[i], err = s.GetRows(&pb.EpochIndex{Idx: receiver, From: 0, To: math.MaxUint64}); err != nil || len(all[i].Rows) == 0 {
+			t.Fatal("could not get rows", err)
+		}
+	}
+	for e := range all[0].Rows {
+		if senders := notify.ReadVector(notify.CombineShares([][]byte{all[0].Rows[e].Val, all[1].Rows[e].Val})); len(senders) != 0 {
+			t.Fatal("completed flip reshared differently", senders)
+		}
+	}
+}
+
+func TestServerDPFNotify(t *testing.T) {
+	size := 50
+	payloadLen := 8
+	rateS := 2
+	servers := make([]*Server, 2)
+	for i := range servers {
+		servers[i] = &Server{MultiClient: false, NumThreads: 1, VerifyColumns: true, RateS: rateS, ServerID: i,
+			Notifications: notify.NewEpochMatrix(size, payloadLen, notify.Schedule{})}
+	}
+	connectPeers(servers, []byte("0123456789abcdef"))
+
+	sender, receiver := uint32(3), uint32(17)
+	payload := bytes.Repeat([]byte{7}, payloadLen)
+	keys := notify.GenNotifyKeys([]uint32{receiver}, uint32(size), rateS)
+	payloadKeys := notify.GenPayloadKeys([]uint32{receiver}, [][]byte{payload}, uint32(size), payloadLen, rateS)
+	triples := notify.GenTriples(rateS)
+	errs := make(chan error, 2)
+	for i, s := range servers {
+		go func(i int, s *Server) {
+			ack, err := s.SetColumn(&pb.NotifyRequest{Idx: sender, Vec: &pb.Vector{}, WriteId: []byte{1},
+				Keys: keys[i], PayloadKeys: payloadKeys[i], Triples: notify.EncodeTriples(triples[i])})
+			if err == nil && !ack.Ok {
+				err = errors.New("write rejected")
+			}
+			errs <- err
+		}(i, s)
+	}
+	for range servers {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows := []*pb.Vector{getRow(t, servers[0], receiver), getRow(t, servers[1], receiver)}
+	if !slices.Equal(notify.ReadVector(notify.CombineShares([][]byte{rows[0].Val, rows[1].Val})), []uint32{sender}) {
+		t.Fatal("wrong row")
+	}
+	if !bytes.Equal(notify.ReadPayloads(notify.CombineShares([][]byte{rows[0].Payload, rows[1].Payload}), []uint32{sender}, payloadLen)[0], payload) {
+		t.Fatal("wrong payload")
+	}
+
+	// more keys than the rate, or keys together with uploaded parts
+	tooMany := notify.GenNotifyKeys(nil, uint32(size), rateS+1)
+	for _, req := range []*pb.NotifyRequest{
+		{Idx: sender, Vec: &pb.Vector{}, Keys: tooMany[0]},
+		{Idx: sender, Vec: &pb.Vector{}, Keys: keys[0], Parts: notify.SplitColumn(nil, uint32(size), 1)},
+		{Idx: sender, Vec: &pb.Vector{}, Keys: [][]byte{keys[0][0][1:]}},
+		{Idx: sender, Vec: &pb.Vector{Payload: make([]byte, size*payloadLen)}, PayloadKeys: payloadKeys[0]},
+	} {
+		if _, err := servers[0].SetColumn(req); !errors.Is(err, ErrInvalidRequest) {
+			t.Fatalf("expected error for malformed request, got %v", err)
+		}
+	}
+}
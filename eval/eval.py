@@ -20,7 +20,9 @@ frame['EXPID'] = (
     frame['malicious'].astype(str) + 
     frame['rate'].astype(str) + 
     frame['multi_client'].astype(str) + 
-    frame['num_threads'].astype(str)
+    frame['num_threads'].astype(str) +
+    frame['single_server'].astype(str) +
+    frame['payload_length'].astype(str)
 )
 
 # add column of total bandwidth values
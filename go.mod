@@ -6,6 +6,7 @@ replace github.com/dkales/dpf-go => ./modules/dpf-go
 
 require (
 	github.com/dkales/dpf-go v0.0.0-20210304170054-6eae87348848
+	github.com/klauspost/cpuid/v2 v2.0.9
 	github.com/lukechampine/fastxor v0.0.0-20210322201628-b664bed5a5cc
 	github.com/spaolacci/murmur3 v1.1.0
 	github.com/stretchr/testify v1.9.0
@@ -16,7 +17,6 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/net v0.28.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
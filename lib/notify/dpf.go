@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"fmt"
+	"math/bits"
+	"sabot/lib/database"
+
+	"github.com/dkales/dpf-go/dpf"
+)
+
+/*
+DPF notifications: instead of a share of the whole column, a sender uploads one DPF key per
+target to each server, the expanded keys of both servers XOR to the vector with the target's bit set.
+Every server expands its keys into the parts of the column (see SplitColumn), so the upload is
+O(RateS * log N) instead of N bits and the parts can be verified like uploaded parts.
+Senders with less than RateS targets add dummy keys, which are the same key for both servers,
+so the expansions cancel out. Payloads are sent the same way with payload DPFs.
+*/
+
+// Generates the DPF keys of the targets and numKeys - len(targets) dummies, returns the encoded keys of each of the two servers
+func GenNotifyKeys(targets []uint32, size uint32, numKeys int) [2][][]byte {
+	if len(targets) > numKeys {
+		panic("notify: more targets than keys")
+	}
+	logN := dpf.DomainBits(uint64(size))
+	var out [2][][]byte
+	for i := 0; i < numKeys; i++ {
+		var alpha uint64
+		if i < len(targets) {
+			alpha = uint64(targets[i])
+		}
+		k0, k1 := dpf.Gen(alpha, logN)
+		if i >= len(targets) {
+			k1 = k0
+		}
+		out[0] = append(out[0], k0.Encode(logN))
+		out[1] = append(out[1], k1.Encode(logN))
+	}
+	return out
+}
+
+// Expands the DPF keys of one server into its shares of the parts of a column with size rows
+func ExpandKeys(keys [][]byte, size int) ([][]byte, error) {
+	logN := dpf.DomainBits(uint64(size))
+	parts := make([][]byte, len(keys))
+	for i, data := range keys {
+		key, err := dpf.ParseKey(data, logN)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %v", i, err)
+		}
+		// leaf j of the expansion is bit j%8 of byte j/8, the notification vectors start with the MSB
+		leaves := dpf.EvalFullN(key, logN, uint64(size))
+		part := make([]byte, (size+7)/8)
+		for j := range part {
+			part[j] = bits.Reverse8(leaves[j])
+		}
+		if size%8 != 0 {
+			part[len(part)-1] &= byte(0xff) << (8 - size%8)
+		}
+		parts[i] = part
+	}
+	return parts, nil
+}
+
+/*
+Generates the payload DPF keys of the targets, payloads[i] is delivered to targets[i],
+and numKeys - len(targets) dummies. Returns the encoded keys of each of the two servers.
+*/
+func GenPayloadKeys(targets []uint32, payloads [][]byte, size uint32, payloadLen int, numKeys int) [2][][]byte {
+	if len(targets) > numKeys || len(payloads) != len(targets) {
+		panic("notify: invalid payload targets")
+	}
+	logN := dpf.DomainBits(uint64(size))
+	var out [2][][]byte
+	for i := 0; i < numKeys; i++ {
+		var alpha uint64
+		beta := make([]byte, payloadLen)
+		if i < len(targets) {
+			alpha = uint64(targets[i])
+			copy(beta, payloads[i])
+		}
+		k0, k1 := dpf.GenPayload(alpha, logN, beta, dpf.GroupXOR)
+		if i >= len(targets) {
+			k1 = k0
+		}
+		for j, k := range []*dpf.PayloadKey{k0, k1} {
+			data, _ := k.MarshalBinary()
+			out[j] = append(out[j], data)
+		}
+	}
+	return out
+}
+
+// Expands the payload DPF keys of one server into its share of the payload column (see CreatePayloadColumn)
+func ExpandPayloadKeys(keys [][]byte, size int, payloadLen int) ([]byte, error) {
+	logN := dpf.DomainBits(uint64(size))
+	col := make([]byte, size*payloadLen)
+	for i, data := range keys {
+		var key dpf.PayloadKey
+		if err := key.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("payload key %d: %v", i, err)
+		}
+		// the expansion has to match the column, a larger key would cost the server 2^LogN leaves
+		if key.Group != dpf.GroupXOR || key.LogN != logN || key.PayloadLen != payloadLen {
+			return nil, fmt.Errorf("payload key %d does not match the payload column", i)
+		}
+		database.XorInto(col, key.EvalFull()[:len(col)])
+	}
+	return col, nil
+}
+
+// returns the XOR of the parts of a column with size rows
+func CombineParts(parts [][]byte, size int) ([]byte, error) {
+	col := make([]byte, (size+7)/8)
+	for i, part := range parts {
+		if len(part) != len(col) {
+			return nil, fmt.Errorf("part %d has length %d", i, len(part))
+		}
+		database.XorInto(col, part)
+	}
+	return col, nil
+}
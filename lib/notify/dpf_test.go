@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestDPFNotify(t *testing.T) {
+	size := 100
+	payloadLen := 8
+	numKeys := 4
+	targets := []uint32{0, 42, 99}
+
+	keys := GenNotifyKeys(targets, uint32(size), numKeys)
+	var parts [2][][]byte
+	for i := range keys {
+		var err error
+		if parts[i], err = ExpandKeys(keys[i], size); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cols := [][]byte{nil, nil}
+	for i := range parts {
+		var err error
+		if cols[i], err = CombineParts(parts[i], size); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := ReadVector(CombineShares(cols)); !slices.Equal(got, targets) {
+		t.Fatal("wrong column", got)
+	}
+	// every part has one target, the dummy part none
+	plain := combinedParts(parts)
+	for p, part := range plain {
+		var want []uint32
+		if p < len(targets) {
+			want = targets[p : p+1]
+		}
+		if got := ReadVector(part); !slices.Equal(got, want) {
+			t.Fatal("part", p, "has targets", got)
+		}
+	}
+	if !verifyParts(t, size, plain, GenTriples(numKeys)) {
+		t.Fatal("expanded parts rejected")
+	}
+
+	payloads := [][]byte{{1}, {2, 2}, bytes.Repeat([]byte{3}, payloadLen)}
+	payloadKeys := GenPayloadKeys(targets, payloads, uint32(size), payloadLen, numKeys)
+	payloadCols := [][]byte{nil, nil}
+	for i := range payloadKeys {
+		var err error
+		if payloadCols[i], err = ExpandPayloadKeys(payloadKeys[i], size, payloadLen); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(CombineShares(payloadCols), CreatePayloadColumn(targets, payloads, uint32(size), payloadLen)) {
+		t.Fatal("wrong payload column")
+	}
+
+	// keys for another column size or payload length
+	if _, err := ExpandKeys(keys[0], 2*size); err == nil {
+		t.Fatal("expected error for key of another domain")
+	}
+	if _, err := ExpandKeys([][]byte{keys[0][0][1:]}, size); err == nil {
+		t.Fatal("expected error for short key")
+	}
+	if _, err := ExpandPayloadKeys(payloadKeys[0], size, payloadLen+8); err == nil {
+		t.Fatal("expected error for payload key of another length")
+	}
+	if _, err := CombineParts([][]byte{make([]byte, 3)}, size); err == nil {
+		t.Fatal("expected error for short part")
+	}
+}
+
+// reconstructs the parts from the shares of both servers
+func combinedParts(shares [2][][]byte) [][]byte {
+	parts := make([][]byte, len(shares[0]))
+	for p := range parts {
+		parts[p] = CombineShares([][]byte{slices.Clone(shares[0][p]), shares[1][p]})
+	}
+	return parts
+}
@@ -0,0 +1,483 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/*
+Schedule of the notification epochs (rounds). Both servers are set up with the same schedule:
+rounds are flipped every Length (aligned to Start) by a coordinator that flips both servers
+(see CloseRound and PublishRound), and both keep the last Retention rounds.
+*/
+type Schedule struct {
+	Start     time.Time     // begin of epoch 0
+	Length    time.Duration // 0: there is only epoch 0, which is read while it is written
+	Retention uint64        // number of readable epochs kept (at least 1)
+}
+
+// returns the epoch at time t
+func (s Schedule) Epoch(t time.Time) uint64 {
+	if s.Length <= 0 || t.Before(s.Start) {
+		return 0
+	}
+	return uint64(t.Sub(s.Start) / s.Length)
+}
+
+// returns the oldest epoch that is kept while cur is the latest readable epoch
+func (s Schedule) Oldest(cur uint64) uint64 {
+	retention := s.Retention
+	if retention == 0 {
+		retention = 1
+	}
+	if cur < retention {
+		return 0
+	}
+	return cur - retention + 1
+}
+
+// returned (wrapped) for epochs that cannot be written or read
+var (
+	ErrEpochExpired    = errors.New("epoch has expired")
+	ErrEpochNotStarted = errors.New("epoch has not started")
+	ErrEpochClosed     = errors.New("epoch is closed for writing")
+	ErrEpochPending    = errors.New("epoch is not published yet")
+)
+
+// the notifications written during one epoch
+type epochMatrices struct {
+	bits     *NotifyMatrix
+	payloads *PayloadMatrix    // nil if notifications carry no payloads (or MACs)
+	writes   map[uint32]uint32 // number of writes per column
+}
+
+/*
+EpochMatrix keeps one notification matrix (and payload matrix) per epoch.
+A column write goes to the matrix of its epoch, so a receiver can fetch the rows of
+all epochs since it was last online and tell new notifications from old ones.
+
+If the schedule has a length, the matrices are double-buffered: writes only go to the
+current epoch and rows can only be read from published epochs, so receivers never see
+half of a round. An epoch is published in two steps, CloseRound ends the writes and
+PublishRound makes it readable once both servers have closed it.
+Otherwise there is only epoch 0, which is read while it is written.
+
+Matrices are only allocated for epochs with writes, epochs older than the retention
+of the schedule are dropped when an epoch is published.
+With Persist, all changes are logged to disk before they are applied (see log.go).
+With EnableResharing, the stored shares are re-randomized whenever an epoch is published.
+With Macs, every cell of the payload matrix also holds a MAC of the bit (see mac.go).
+*/
+type EpochMatrix struct {
+	NumRows    int
+	PayloadLen int
+	Schedule   Schedule
+	Macs       bool   // has to be set before the first write
+	current    uint64 // epoch that is written
+	published  uint64 // epochs before are readable
+	epochs     map[uint64]*epochMatrices
+	log        *writeLog // nil if the matrices are not persisted
+	reshareKey []byte    // nil if resharing is disabled (see reshare.go)
+	// own resharing randomness of the closed and the last published epoch
+	reshareNonces map[uint64][]byte
+	// columns share the words of a row, so writes are serialized
+	mu sync.RWMutex
+}
+
+func NewEpochMatrix(size int, payloadLen int, schedule Schedule) *EpochMatrix {
+	return &EpochMatrix{
+		NumRows:    size,
+		PayloadLen: payloadLen,
+		Schedule:   schedule,
+		epochs:     make(map[uint64]*epochMatrices),
+		// filled when epochs are closed with resharing
+		reshareNonces: make(map[uint64][]byte),
+	}
+}
+
+// returns the length of a cell of the payload matrix, the payload followed by the MAC if enabled
+func (eM *EpochMatrix) CellLen() int {
+	if eM.Macs {
+		return eM.PayloadLen + 2*MacLen
+	}
+	return eM.PayloadLen
+}
+
+// returns whether epochs are double-buffered
+func (eM *EpochMatrix) Buffered() bool {
+	return eM.Schedule.Length > 0
+}
+
+/*
+Returns the oldest kept epoch, the epoch that is written and the last readable epoch + 1,
+i.e. the epochs [oldest, readable) can be read
+*/
+func (eM *EpochMatrix) Window() (uint64, uint64, uint64) {
+	eM.mu.RLock()
+	defer eM.mu.RUnlock()
+	return eM.oldest(), eM.current, eM.readable()
+}
+
+func (eM *EpochMatrix) readable() uint64 {
+	if !eM.Buffered() {
+		return eM.current + 1
+	}
+	return eM.published
+}
+
+func (eM *EpochMatrix) oldest() uint64 {
+	if r := eM.readable(); r > 0 {
+		return eM.Schedule.Oldest(r - 1)
+	}
+	return 0
+}
+
+func (eM *EpochMatrix) checkWrite(e uint64) error {
+	if e > eM.current {
+		return fmt.Errorf("%w: epoch %d, current epoch %d", ErrEpochNotStarted, e, eM.current)
+	}
+	if e < eM.current {
+		return fmt.Errorf("%w: epoch %d, current epoch %d", ErrEpochClosed, e, eM.current)
+	}
+	return nil
+}
+
+func (eM *EpochMatrix) checkRead(e uint64) error {
+	if oldest := eM.oldest(); e < oldest {
+		return fmt.Errorf("%w: epoch %d, oldest epoch %d", ErrEpochExpired, e, oldest)
+	}
+	if e > eM.current {
+		return fmt.Errorf("%w: epoch %d, current epoch %d", ErrEpochNotStarted, e, eM.current)
+	}
+	if e >= eM.readable() {
+		return fmt.Errorf("%w: epoch %d", ErrEpochPending, e)
+	}
+	return nil
+}
+
+// returns an error if epoch e cannot be written, together with the current epoch
+func (eM *EpochMatrix) CheckWrite(e uint64) (uint64, error) {
+	eM.mu.RLock()
+	defer eM.mu.RUnlock()
+	return eM.current, eM.checkWrite(e)
+}
+
+/*
+Replaces column cIdx of epoch e, which has to be the current epoch,
+writes of earlier epochs are kept until they expire.
+payloadCol is the payload column (see PayloadMatrix.SetColumn) with cells of CellLen bytes,
+nil clears the payloads (and gives the cells new MAC keys)
+*/
+func (eM *EpochMatrix) SetColumn(e uint64, cIdx int, col []byte, payloadCol []byte) error {
+	eM.mu.Lock()
+	defer eM.mu.Unlock()
+	if err := eM.checkWrite(e); err != nil {
+		return err
+	}
+	if cIdx < 0 || cIdx >= eM.NumRows {
+		return fmt.Errorf("invalid column %d", cIdx)
+	}
+	if payloadCol != nil && (eM.CellLen() == 0 || len(payloadCol) != eM.NumRows*eM.CellLen()) {
+		return fmt.Errorf("payload column has length %d", len(payloadCol))
+	}
+	if eM.log != nil {
+		if err := eM.log.append(encodeWrite(e, cIdx, col, payloadCol)); err != nil {
+			return err
+		}
+	}
+	eM.setColumn(e, cIdx, col, payloadCol)
+	if eM.log != nil {
+		eM.log.maybeCompact(eM, false)
+	}
+	return nil
+}
+
+func (eM *EpochMatrix) setColumn(e uint64, cIdx int, col []byte, payloadCol []byte) {
+	m := eM.epochs[e]
+	if m == nil {
+		m = eM.newEpoch()
+		eM.epochs[e] = m
+	}
+	m.bits.SetColumn(cIdx, col)
+	if m.payloads != nil {
+		if payloadCol == nil {
+			eM.clearPayloads(m, cIdx)
+		} else {
+			m.payloads.SetColumn(cIdx, payloadCol)
+		}
+	}
+	m.writes[uint32(cIdx)]++
+}
+
+// allocates the matrices of an epoch, the cells get random MAC keys
+func (eM *EpochMatrix) newEpoch() *epochMatrices {
+	m := &epochMatrices{bits: NewMatrix(eM.NumRows), writes: make(map[uint32]uint32)}
+	if eM.CellLen() > 0 {
+		m.payloads = NewPayloadMatrix(eM.NumRows, eM.CellLen())
+		if eM.Macs {
+			randomizeKeys(m.payloads.cells, eM.NumRows, eM.PayloadLen, -1)
+		}
+	}
+	return m
+}
+
+// clears the payloads of column cIdx, a cleared cell must not have a known MAC key
+func (eM *EpochMatrix) clearPayloads(m *epochMatrices, cIdx int) {
+	m.payloads.ClearColumn(cIdx)
+	if eM.Macs {
+		randomizeKeys(m.payloads.cells, eM.NumRows, eM.PayloadLen, cIdx)
+	}
+}
+
+/*
+Returns row rIdx of epoch e and its payload row (nil if payloads and MACs are disabled).
+Epochs without any writes have all-zero rows (with random MAC keys), which are valid shares of an empty row.
+*/
+func (eM *EpochMatrix) GetRow(e uint64, rIdx uint32) ([]byte, []byte, error) {
+	eM.mu.RLock()
+	defer eM.mu.RUnlock()
+	return eM.getRow(e, rIdx)
+}
+
+func (eM *EpochMatrix) getRow(e uint64, rIdx uint32) ([]byte, []byte, error) {
+	if err := eM.checkRead(e); err != nil {
+		return nil, nil, err
+	}
+	if rIdx >= uint32(eM.NumRows) {
+		return nil, nil, fmt.Errorf("invalid row %d", rIdx)
+	}
+	m := eM.epochs[e]
+	if m == nil {
+		return eM.emptyRow()
+	}
+	var payloads []byte
+	if m.payloads != nil {
+		payloads = m.payloads.GetRow(rIdx)
+	}
+	return m.bits.GetRow(rIdx), payloads, nil
+}
+
+func (eM *EpochMatrix) emptyRow() ([]byte, []byte, error) {
+	var payloads []byte
+	if eM.CellLen() > 0 {
+		payloads = make([]byte, eM.NumRows*eM.CellLen())
+		if eM.Macs {
+			randomizeKeys(payloads, eM.NumRows, eM.PayloadLen, -1)
+		}
+	}
+	return make([]byte, (eM.NumRows+7)/8), payloads, nil
+}
+
+/*
+Like GetRow, for the latest readable epoch, which is returned along with the row.
+The row is empty if no epoch is readable yet.
+*/
+func (eM *EpochMatrix) GetLatestRow(rIdx uint32) ([]byte, []byte, uint64, error) {
+	eM.mu.RLock()
+	defer eM.mu.RUnlock()
+	r := eM.readable()
+	if r == 0 {
+		if rIdx >= uint32(eM.NumRows) {
+			return nil, nil, 0, fmt.Errorf("invalid row %d", rIdx)
+		}
+		row, payloads, err := eM.emptyRow()
+		return row, payloads, 0, err
+	}
+	row, payloads, err := eM.getRow(r-1, rIdx)
+	return row, payloads, r - 1, err
+}
+
+/*
+CloseRound ends the writes to the current epoch and starts the next one.
+Returns the closed epoch and the number of writes to each of its columns.
+If a closed epoch has not been published yet, no further epoch is closed and that epoch
+is returned again, so a coordinator can repeat a flip that was interrupted.
+Nor is an epoch closed while the log could not be compacted after a publication.
+*/
+func (eM *EpochMatrix) CloseRound() (uint64, map[uint32]uint32, error) {
+	eM.mu.Lock()
+	defer eM.mu.Unlock()
+	if !eM.Buffered() {
+		return 0, nil, errors.New("epochs are not enabled")
+	}
+	// no further epoch is written while the log still holds shares that should have been erased
+	if eM.log != nil && eM.log.erase {
+		if err := eM.log.maybeCompact(eM, true); err != nil {
+			return 0, nil, err
+		}
+	}
+	// an epoch closed before resharing was enabled is closed again to draw its resharing randomness
+	if eM.published == eM.current || (eM.reshareKey != nil && eM.reshareNonces[eM.published] == nil) {
+		var nonce []byte
+		if eM.reshareKey != nil {
+			nonce = newReshareNonce()
+		}
+		if eM.log != nil {
+			if err := eM.log.append(encodeClose(eM.published, nonce)); err != nil {
+				return 0, nil, err
+			}
+		}
+		if nonce != nil {
+			eM.reshareNonces[eM.published] = nonce
+		}
+		eM.current = eM.published + 1
+	}
+	e := eM.published
+	writes := make(map[uint32]uint32)
+	if m := eM.epochs[e]; m != nil {
+		for c, n := range m.writes {
+			writes[c] = n
+		}
+	}
+	return e, writes, nil
+}
+
+/*
+PublishRound makes the closed epoch e readable. writes are the numbers of writes per column
+all servers agree on, all other columns are cleared: a sender whose request crossed the flip
+only wrote its share to some of the servers, and a single share reconstructs to garbage.
+writes can only drop columns: a column the closed epoch has not written that often is rejected.
+Publishing an epoch twice has no effect.
+With resharing, nonce is the fresh randomness of all servers the key is ratcheted with (see reshare.go).
+An error after the epoch was published means that the log still holds expired or reshared shares.
+*/
+func (eM *EpochMatrix) PublishRound(e uint64, writes map[uint32]uint32, nonce []byte) error {
+	eM.mu.Lock()
+	defer eM.mu.Unlock()
+	if !eM.Buffered() {
+		return errors.New("epochs are not enabled")
+	}
+	if e < eM.published {
+		return nil
+	}
+	if e != eM.published || e == eM.current {
+		return fmt.Errorf("%w: epoch %d is not closed", ErrEpochNotStarted, e)
+	}
+	for c, n := range writes {
+		if m := eM.epochs[e]; m == nil || m.writes[c] != n {
+			return fmt.Errorf("column %d of epoch %d was not written %d times", c, e, n)
+		}
+	}
+	if eM.log != nil {
+		if err := eM.log.append(encodePublish(e, writes, nonce)); err != nil {
+			return err
+		}
+	}
+	expired := eM.publishRound(e, writes, nonce)
+	if eM.log != nil {
+		// the shares and the key before the resharing must not stay on disk
+		return eM.log.maybeCompact(eM, expired || eM.reshareKey != nil)
+	}
+	return nil
+}
+
+// returns whether epochs were dropped
+func (eM *EpochMatrix) publishRound(e uint64, writes map[uint32]uint32, nonce []byte) bool {
+	if m := eM.epochs[e]; m != nil {
+		for c, n := range m.writes {
+			if writes[c] != n {
+				m.bits.ClearColumn(int(c))
+				if m.payloads != nil {
+					eM.clearPayloads(m, int(c))
+				}
+				delete(m.writes, c)
+			}
+		}
+	}
+	eM.published = e + 1
+	oldest := eM.oldest()
+	dropped := false
+	for k := range eM.epochs {
+		if k < oldest {
+			delete(eM.epochs, k)
+			dropped = true
+		}
+	}
+	if eM.reshareKey != nil {
+		eM.reshare(nonce)
+	}
+	for k := range eM.reshareNonces {
+		if k < e {
+			delete(eM.reshareNonces, k)
+		}
+	}
+	return dropped
+}
+
+/*
+Returns the published epochs, i.e. the epochs before are readable (and durable if the matrix
+is persisted), the epoch that is written and the writes per column of the last published epoch
+*/
+func (eM *EpochMatrix) Published() (uint64, uint64, map[uint32]uint32) {
+	eM.mu.RLock()
+	defer eM.mu.RUnlock()
+	writes := make(map[uint32]uint32)
+	if eM.published > 0 {
+		if m := eM.epochs[eM.published-1]; m != nil {
+			for c, n := range m.writes {
+				writes[c] = n
+			}
+		}
+	}
+	return eM.published, eM.current, writes
+}
+
+/*
+Mailbox remembers which notifications a receiver has already read, so every notification
+is returned only once. Rows of the current epoch can still change if epochs are not
+double-buffered, so that epoch is read again next time and only senders that were not
+returned before are reported.
+*/
+type Mailbox struct {
+	Next uint64          // first epoch that has not been read completely
+	seen map[uint32]bool // senders already returned from epoch Next
+}
+
+/*
+Read takes the reconstructed rows of the epochs from, from+1, ... and the epoch currently written by
+the servers and returns the senders that were not read before and for each sender the latest epoch
+it notified in
+*/
+func (mb *Mailbox) Read(from uint64, rows [][]byte, current uint64) ([]uint32, []uint64) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	latest := make(map[uint32]uint64)
+	var senders []uint32
+	for i, row := range rows {
+		epoch := from + uint64(i)
+		for _, sender := range ReadVector(row) {
+			if epoch == mb.Next && mb.seen[sender] {
+				continue
+			}
+			if _, ok := latest[sender]; !ok {
+				senders = append(senders, sender)
+			}
+			latest[sender] = epoch
+		}
+	}
+	epochs := make([]uint64, len(senders))
+	for i, sender := range senders {
+		epochs[i] = latest[sender]
+	}
+
+	last := from + uint64(len(rows)) - 1
+	if last < current {
+		// all rows are final
+		mb.Next, mb.seen = last+1, nil
+		return senders, epochs
+	}
+	if last > mb.Next {
+		mb.seen = nil
+	}
+	if mb.seen == nil {
+		mb.seen = make(map[uint32]bool)
+	}
+	mb.Next = last
+	for _, sender := range ReadVector(rows[len(rows)-1]) {
+		mb.seen[sender] = true
+	}
+	return senders, epochs
+}
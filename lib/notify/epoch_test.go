@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEpochMatrix(t *testing.T) {
+	size := 100
+	eM := NewEpochMatrix(size, 0, Schedule{Start: time.Unix(1700000000, 0), Length: time.Minute, Retention: 2})
+
+	if err := eM.SetColumn(0, 3, CreateVector([]uint32{7}, uint32(size)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := eM.SetColumn(1, 3, CreateVector([]uint32{7}, uint32(size)), nil); !errors.Is(err, ErrEpochNotStarted) {
+		t.Fatal("expected error for future epoch, got", err)
+	}
+	// the written epoch cannot be read before it is published
+	if _, _, err := eM.GetRow(0, 7); !errors.Is(err, ErrEpochPending) {
+		t.Fatal("expected error for unpublished epoch, got", err)
+	}
+	if row, _, _, _ := eM.GetLatestRow(7); len(ReadVector(row)) != 0 {
+		t.Fatal("unpublished notification read")
+	}
+
+	// a write in the next epoch does not overwrite the old one
+	e, writes, err := eM.CloseRound()
+	if err != nil || e != 0 || writes[3] != 1 || len(writes) != 1 {
+		t.Fatal("wrong closed epoch", e, writes, err)
+	}
+	if err := eM.SetColumn(0, 4, CreateVector([]uint32{7}, uint32(size)), nil); !errors.Is(err, ErrEpochClosed) {
+		t.Fatal("expected error for closed epoch, got", err)
+	}
+	if err := eM.SetColumn(1, 3, CreateVector([]uint32{8}, uint32(size)), nil); err != nil {
+		t.Fatal(err)
+	}
+	// closing again before publishing returns the same epoch
+	if e, _, _ := eM.CloseRound(); e != 0 {
+		t.Fatal("interrupted flip not repeated", e)
+	}
+	if err := eM.PublishRound(0, writes, nil); err != nil {
+		t.Fatal(err)
+	}
+	if row, _, e, _ := eM.GetLatestRow(7); e != 0 || !slices.Equal(ReadVector(row), []uint32{3}) {
+		t.Fatal("wrong latest row")
+	}
+
+	// columns the servers disagree on are cleared
+	if err := eM.SetColumn(1, 5, CreateVector([]uint32{8}, uint32(size)), nil); err != nil {
+		t.Fatal(err)
+	}
+	e, writes, _ = eM.CloseRound()
+	// writes the epoch does not have are rejected
+	if err := eM.PublishRound(e, map[uint32]uint32{5: 2}, nil); err == nil {
+		t.Fatal("publication with unknown writes accepted")
+	}
+	delete(writes, 5)
+	if err := eM.PublishRound(e, writes, nil); err != nil {
+		t.Fatal(err)
+	}
+	if row, _, _ := eM.GetRow(1, 8); !slices.Equal(ReadVector(row), []uint32{3}) {
+		t.Fatal("wrong row in epoch 1")
+	}
+	if row, _, _ := eM.GetRow(1, 7); len(ReadVector(row)) != 0 {
+		t.Fatal("old notification in new epoch")
+	}
+
+	// epoch 0 expires, epochs without writes are empty
+	e, writes, _ = eM.CloseRound()
+	eM.PublishRound(e, writes, nil)
+	if _, _, err := eM.GetRow(0, 7); !errors.Is(err, ErrEpochExpired) {
+		t.Fatal("expected error for expired epoch, got", err)
+	}
+	if oldest, current, readable := eM.Window(); oldest != 1 || current != 3 || readable != 3 {
+		t.Fatal("wrong window", oldest, current, readable)
+	}
+	if row, _, err := eM.GetRow(2, 8); err != nil || len(row) != (size+7)/8 || len(ReadVector(row)) != 0 {
+		t.Fatal("wrong empty row", err)
+	}
+	if len(eM.epochs) != 1 {
+		t.Fatal("expired epoch not dropped")
+	}
+}
+
+func TestEpochMatrixUnbuffered(t *testing.T) {
+	size := 20
+	eM := NewEpochMatrix(size, 4, Schedule{})
+	if err := eM.SetColumn(0, 3, CreateVector([]uint32{7}, uint32(size)), CreatePayloadColumn([]uint32{7}, [][]byte{{1, 2}}, uint32(size), 4)); err != nil {
+		t.Fatal(err)
+	}
+	// notifications are read while they are written
+	row, payloads, e, err := eM.GetLatestRow(7)
+	if err != nil || e != 0 || !slices.Equal(ReadVector(row), []uint32{3}) || !slices.Equal(ReadPayloads(payloads, []uint32{3}, 4)[0], []byte{1, 2, 0, 0}) {
+		t.Fatal("wrong row", err)
+	}
+	if _, _, err := eM.CloseRound(); err == nil {
+		t.Fatal("expected error for round flip without epochs")
+	}
+}
+
+func TestMailbox(t *testing.T) {
+	size := uint32(20)
+	var mb Mailbox
+
+	// epoch 1 is still written and read again
+	senders, epochs := mb.Read(0, [][]byte{CreateVector([]uint32{1, 2}, size), CreateVector([]uint32{2, 3}, size)}, 1)
+	if !slices.Equal(senders, []uint32{1, 2, 3}) || !slices.Equal(epochs, []uint64{0, 1, 1}) {
+		t.Fatal("wrong first read", senders, epochs)
+	}
+	senders, _ = mb.Read(1, [][]byte{CreateVector([]uint32{2, 3, 4}, size)}, 1)
+	if !slices.Equal(senders, []uint32{4}) {
+		t.Fatal("notifications read twice", senders)
+	}
+	senders, epochs = mb.Read(1, [][]byte{CreateVector([]uint32{2, 3, 4}, size), CreateVector([]uint32{3}, size)}, 2)
+	if !slices.Equal(senders, []uint32{3}) || !slices.Equal(epochs, []uint64{2}) {
+		t.Fatal("wrong read of new epoch", senders, epochs)
+	}
+	if mb.Next != 2 {
+		t.Fatal("wrong next epoch", mb.Next)
+	}
+
+	// published epochs are final and not read again
+	senders, _ = mb.Read(2, [][]byte{CreateVector([]uint32{3, 5}, size)}, 4)
+	if !slices.Equal(senders, []uint32{5}) || mb.Next != 3 {
+		t.Fatal("wrong read of published epoch", senders, mb.Next)
+	}
+	if senders, _ = mb.Read(3, nil, 4); senders != nil || mb.Next != 3 {
+		t.Fatal("wrong read without rows")
+	}
+}
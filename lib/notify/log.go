@@ -0,0 +1,470 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+/*
+Write-ahead log of an EpochMatrix: every column write and round flip is appended and
+synced to disk before it is applied, so everything a server acknowledged survives a restart.
+The log starts with a header, followed by records len(4) | crc32(4) | type(1) | body,
+all integers are little-endian. A torn record at the end (crash during an append) is dropped.
+Once epochs expire (or the shares are reshared), the log is replaced by a snapshot of the kept epochs.
+*/
+
+const (
+	logMagic   = "SABOTLOG"
+	logVersion = 1
+	// logs of matrices with MACs have another version, their cells are longer
+	logVersionMacs = 2
+	// magic | version(1) | numRows(4) | payloadLen(4)
+	logHeaderLen = len(logMagic) + 9
+	// words of the bit matrix encoded at once when a snapshot is written
+	snapshotChunkWords = 1 << 12
+)
+
+// record types
+const (
+	recWrite    = 1 // epoch(8) | column(4) | hasPayload(1) | len(4) | col | payload column
+	recClose    = 2 // epoch(8) | own resharing randomness of the epoch
+	recPublish  = 3 // epoch(8) | writes | randomness of the resharing
+	recState    = 4 // current(8) | published(8)
+	recSnapshot = 5 // epoch(8) | writes | bit matrix | payload matrix, writes are n(4) | n * (column(4) | count(4))
+	recReshare  = 6 // published(8) | resharing key | n * (epoch(8) | own resharing randomness)
+)
+
+type writeLog struct {
+	path      string
+	f         *os.File
+	size      int  // bytes in the log
+	compactAt int  // the log is compacted once it is larger
+	erase     bool // a forced compaction failed, the log still holds shares or keys that have to be erased
+}
+
+func encodeWrite(e uint64, cIdx int, col []byte, payloadCol []byte) []byte {
+	rec := []byte{recWrite}
+	rec = binary.LittleEndian.AppendUint64(rec, e)
+	rec = binary.LittleEndian.AppendUint32(rec, uint32(cIdx))
+	if payloadCol != nil {
+		rec = append(rec, 1)
+	} else {
+		rec = append(rec, 0)
+	}
+	rec = binary.LittleEndian.AppendUint32(rec, uint32(len(col)))
+	rec = append(rec, col...)
+	return append(rec, payloadCol...)
+}
+
+func encodeClose(e uint64, nonce []byte) []byte {
+	return append(binary.LittleEndian.AppendUint64([]byte{recClose}, e), nonce...)
+}
+
+func appendWrites(rec []byte, writes map[uint32]uint32) []byte {
+	columns := make([]uint32, 0, len(writes))
+	for c := range writes {
+		columns = append(columns, c)
+	}
+	slices.Sort(columns)
+	rec = binary.LittleEndian.AppendUint32(rec, uint32(len(columns)))
+	for _, c := range columns {
+		rec = binary.LittleEndian.AppendUint32(rec, c)
+		rec = binary.LittleEndian.AppendUint32(rec, writes[c])
+	}
+	return rec
+}
+
+func encodePublish(e uint64, writes map[uint32]uint32, nonce []byte) []byte {
+	return append(appendWrites(binary.LittleEndian.AppendUint64([]byte{recPublish}, e), writes), nonce...)
+}
+
+func encodeState(current, published uint64) []byte {
+	rec := binary.LittleEndian.AppendUint64([]byte{recState}, current)
+	return binary.LittleEndian.AppendUint64(rec, published)
+}
+
+func encodeReshare(published uint64, key []byte, nonces map[uint64][]byte) []byte {
+	rec := append(binary.LittleEndian.AppendUint64([]byte{recReshare}, published), key...)
+	epochs := make([]uint64, 0, len(nonces))
+	for e := range nonces {
+		epochs = append(epochs, e)
+	}
+	slices.Sort(epochs)
+	for _, e := range epochs {
+		rec = append(binary.LittleEndian.AppendUint64(rec, e), nonces[e]...)
+	}
+	return rec
+}
+
+/*
+Passes the snapshot record of an epoch to fn piece by piece, so a snapshot is never
+held in memory twice: the payload cells are passed as they are, the bit matrix in chunks.
+*/
+func encodeSnapshot(e uint64, m *epochMatrices, fn func([]byte)) {
+	fn(appendWrites(binary.LittleEndian.AppendUint64([]byte{recSnapshot}, e), m.writes))
+	chunk := make([]byte, 0, 8*snapshotChunkWords)
+	for i, word := range m.bits.words {
+		chunk = binary.LittleEndian.AppendUint64(chunk, word)
+		if len(chunk) == cap(chunk) || i == len(m.bits.words)-1 {
+			fn(chunk)
+			chunk = chunk[:0]
+		}
+	}
+	if m.payloads != nil {
+		fn(m.payloads.cells)
+	}
+}
+
+// writes the framed snapshot record of an epoch, it is encoded twice: once for its length and checksum, once to write it
+func writeSnapshot(w io.Writer, e uint64, m *epochMatrices) (int, error) {
+	n, crc := 0, uint32(0)
+	encodeSnapshot(e, m, func(p []byte) {
+		n += len(p)
+		crc = crc32.Update(crc, crc32.IEEETable, p)
+	})
+	header := binary.LittleEndian.AppendUint32(nil, uint32(n))
+	if _, err := w.Write(binary.LittleEndian.AppendUint32(header, crc)); err != nil {
+		return 0, err
+	}
+	var err error
+	encodeSnapshot(e, m, func(p []byte) {
+		if err == nil {
+			_, err = w.Write(p)
+		}
+	})
+	return 8 + n, err
+}
+
+func encodeHeader(numRows, payloadLen int, macs bool) []byte {
+	header := append([]byte(logMagic), logVersion)
+	if macs {
+		header[len(logMagic)] = logVersionMacs
+	}
+	header = binary.LittleEndian.AppendUint32(header, uint32(numRows))
+	return binary.LittleEndian.AppendUint32(header, uint32(payloadLen))
+}
+
+func frame(rec []byte) []byte {
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(rec)))
+	out = binary.LittleEndian.AppendUint32(out, crc32.ChecksumIEEE(rec))
+	return append(out, rec...)
+}
+
+// appends a record and waits until it is on disk
+func (l *writeLog) append(rec []byte) error {
+	if _, err := l.f.Write(frame(rec)); err != nil {
+		return fmt.Errorf("could not write log: %v", err)
+	}
+	if err := l.f.Sync(); err != nil {
+		return fmt.Errorf("could not sync log: %v", err)
+	}
+	l.size += 8 + len(rec)
+	return nil
+}
+
+func (l *writeLog) grown(size int) {
+	l.size = size
+	l.compactAt = 2*size + 1<<20
+}
+
+/*
+Compacts the log if it holds mostly overwritten or expired data, or if forced.
+A failed compaction only costs disk space, unless it was forced to erase expired or reshared shares:
+then the error is returned and no further epoch is closed until a compaction succeeds (see CloseRound).
+*/
+func (l *writeLog) maybeCompact(eM *EpochMatrix, force bool) error {
+	if !force && l.size <= l.compactAt {
+		return nil
+	}
+	if err := l.compact(eM); err != nil {
+		if !force {
+			log.Println("could not compact notification log:", err)
+			return nil
+		}
+		l.erase = true
+		return fmt.Errorf("could not compact notification log: %v", err)
+	}
+	l.erase = false
+	return nil
+}
+
+// replaces the log with a snapshot of the matrices, the old log is kept until the snapshot is on disk
+func (l *writeLog) compact(eM *EpochMatrix) error {
+	tmp := l.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	// the matrices are streamed to the file instead of being copied into one buffer
+	w := bufio.NewWriter(f)
+	data := encodeHeader(eM.NumRows, eM.PayloadLen, eM.Macs)
+	data = append(data, frame(encodeState(eM.current, eM.published))...)
+	if eM.reshareKey != nil {
+		data = append(data, frame(encodeReshare(eM.published, eM.reshareKey, eM.reshareNonces))...)
+	}
+	w.Write(data)
+	size := len(data)
+	epochs := make([]uint64, 0, len(eM.epochs))
+	for e := range eM.epochs {
+		epochs = append(epochs, e)
+	}
+	slices.Sort(epochs)
+	for _, e := range epochs {
+		n, err := writeSnapshot(w, e, eM.epochs[e])
+		if err != nil {
+			f.Close()
+			return err
+		}
+		size += n
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		f.Close()
+		return err
+	}
+	// the rename is only durable once the directory is synced
+	if dir, err := os.Open(filepath.Dir(l.path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	if l.f != nil {
+		l.f.Close()
+	}
+	l.f = f
+	l.grown(size)
+	return nil
+}
+
+/*
+Reads the records after the header from r until the end of the log or a torn record, returns the length of the valid log.
+Records are read one at a time into a buffer that is reused, so only the largest record is held in memory
+next to the matrix, size is the length of the log file and bounds the length of a record.
+*/
+func (eM *EpochMatrix) replay(r io.Reader, size int) (int, error) {
+	off := logHeaderLen
+	var frame [8]byte
+	var rec []byte
+	// the records hold shares and resharing keys
+	defer func() { clear(rec) }()
+	for {
+		if _, err := io.ReadFull(r, frame[:]); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return off, nil
+		} else if err != nil {
+			return 0, err
+		}
+		n := int(binary.LittleEndian.Uint32(frame[:]))
+		if n == 0 || off+8+n > size {
+			return off, nil
+		}
+		if cap(rec) < n {
+			clear(rec)
+			rec = make([]byte, n)
+		}
+		rec = rec[:n]
+		if _, err := io.ReadFull(r, rec); errors.Is(err, io.ErrUnexpectedEOF) {
+			return off, nil
+		} else if err != nil {
+			return 0, err
+		}
+		if crc32.ChecksumIEEE(rec) != binary.LittleEndian.Uint32(frame[4:]) {
+			return off, nil
+		}
+		if err := eM.apply(rec); err != nil {
+			return 0, fmt.Errorf("corrupt log record at offset %d: %v", off, err)
+		}
+		off += 8 + n
+	}
+}
+
+var errRecord = errors.New("invalid record")
+
+func readWrites(rec []byte) (map[uint32]uint32, []byte, error) {
+	if len(rec) < 4 {
+		return nil, nil, errRecord
+	}
+	n := int(binary.LittleEndian.Uint32(rec))
+	rec = rec[4:]
+	if len(rec) < 8*n {
+		return nil, nil, errRecord
+	}
+	writes := make(map[uint32]uint32, n)
+	for i := 0; i < n; i++ {
+		writes[binary.LittleEndian.Uint32(rec[8*i:])] = binary.LittleEndian.Uint32(rec[8*i+4:])
+	}
+	return writes, rec[8*n:], nil
+}
+
+// applies a log record without logging it again
+func (eM *EpochMatrix) apply(rec []byte) error {
+	if len(rec) < 9 {
+		return errRecord
+	}
+	e := binary.LittleEndian.Uint64(rec[1:])
+	body := rec[9:]
+	switch rec[0] {
+	case recWrite:
+		if len(body) < 9 {
+			return errRecord
+		}
+		cIdx := int(binary.LittleEndian.Uint32(body))
+		colLen := int(binary.LittleEndian.Uint32(body[5:]))
+		if cIdx >= eM.NumRows || len(body) < 9+colLen {
+			return errRecord
+		}
+		col := body[9 : 9+colLen]
+		var payloadCol []byte
+		if body[4] == 1 {
+			payloadCol = body[9+colLen:]
+			if eM.CellLen() == 0 || len(payloadCol) != eM.NumRows*eM.CellLen() {
+				return errRecord
+			}
+		}
+		eM.setColumn(e, cIdx, col, payloadCol)
+	case recClose:
+		if len(body) != 0 && len(body) != reshareNonceLen {
+			return errRecord
+		}
+		if len(body) != 0 {
+			eM.reshareNonces[e] = slices.Clone(body)
+		}
+		eM.current = e + 1
+	case recPublish:
+		writes, nonce, err := readWrites(body)
+		if err != nil {
+			return err
+		}
+		eM.publishRound(e, writes, nonce)
+	case recState:
+		if len(body) < 8 {
+			return errRecord
+		}
+		eM.current, eM.published = e, binary.LittleEndian.Uint64(body)
+	case recReshare:
+		if len(body) < reshareKeyLen || (len(body)-reshareKeyLen)%(8+reshareNonceLen) != 0 {
+			return errRecord
+		}
+		eM.reshareKey = slices.Clone(body[:reshareKeyLen])
+		for rest := body[reshareKeyLen:]; len(rest) > 0; rest = rest[8+reshareNonceLen:] {
+			eM.reshareNonces[binary.LittleEndian.Uint64(rest)] = slices.Clone(rest[8 : 8+reshareNonceLen])
+		}
+	case recSnapshot:
+		writes, body, err := readWrites(body)
+		if err != nil {
+			return err
+		}
+		m := &epochMatrices{bits: NewMatrix(eM.NumRows), writes: writes}
+		if len(body) != 8*len(m.bits.words)+eM.NumRows*eM.NumRows*eM.CellLen() {
+			return errRecord
+		}
+		for i := range m.bits.words {
+			m.bits.words[i] = binary.LittleEndian.Uint64(body[8*i:])
+		}
+		if eM.CellLen() > 0 {
+			m.payloads = NewPayloadMatrix(eM.NumRows, eM.CellLen())
+			copy(m.payloads.cells, body[8*len(m.bits.words):])
+		}
+		eM.epochs[e] = m
+	default:
+		return errRecord
+	}
+	return nil
+}
+
+/*
+Persist replays the log at path into the matrix and from then on logs all changes to it.
+If there is no log yet, a new log with the current state of the matrix is started.
+A log that belongs to a matrix of another size, payload length or MAC setting is an error.
+The resharing key of the log replaces the key of the matrix.
+*/
+func (eM *EpochMatrix) Persist(path string) error {
+	eM.mu.Lock()
+	defer eM.mu.Unlock()
+	l := &writeLog{path: path}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := l.compact(eM); err != nil {
+			return err
+		}
+		eM.log = l
+		return nil
+	} else if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	// the log is replayed from the file, it can be much larger than the memory left next to the matrix
+	r := bufio.NewReader(f)
+	header := make([]byte, logHeaderLen)
+	// the log holds acknowledged notifications, it is not replaced because of a configuration mistake
+	if _, err := io.ReadFull(r, header); err != nil || !bytes.Equal(header, encodeHeader(eM.NumRows, eM.PayloadLen, eM.Macs)) {
+		f.Close()
+		return fmt.Errorf("notification log %s is for a matrix of another size, payload length or MAC setting, remove it to start a new log", path)
+	}
+
+	// publications in the log are only reshared if the log was reshared
+	key := eM.reshareKey
+	eM.reshareKey = nil
+	end, err := eM.replay(r, int(info.Size()))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f = f
+	// drop a torn record, so new records are appended to the valid log
+	if err := l.f.Truncate(int64(end)); err != nil {
+		l.f.Close()
+		return err
+	}
+	if _, err := l.f.Seek(int64(end), 0); err != nil {
+		l.f.Close()
+		return err
+	}
+	l.grown(end)
+	eM.log = l
+	if eM.reshareKey == nil && key != nil {
+		// resharing starts now, the key has to be on disk before the next publication
+		eM.reshareKey = key
+		return l.compact(eM)
+	}
+	return nil
+}
+
+// returns whether the matrix is logged to disk
+func (eM *EpochMatrix) Persistent() bool {
+	eM.mu.RLock()
+	defer eM.mu.RUnlock()
+	return eM.log != nil
+}
+
+// Close closes the log of a persisted matrix
+func (eM *EpochMatrix) Close() error {
+	eM.mu.Lock()
+	defer eM.mu.Unlock()
+	if eM.log == nil {
+		return nil
+	}
+	err := eM.log.f.Close()
+	eM.log = nil
+	return err
+}
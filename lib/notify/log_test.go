@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPersist(t *testing.T) {
+	size := 30
+	payloadLen := 4
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	schedule := Schedule{Length: time.Hour, Retention: 2}
+	recover := func() *EpochMatrix {
+		eM := NewEpochMatrix(size, payloadLen, schedule)
+		if err := eM.Persist(path); err != nil {
+			t.Fatal(err)
+		}
+		return eM
+	}
+	write := func(eM *EpochMatrix, e uint64, sender, receiver uint32) {
+		payloads := CreatePayloadColumn([]uint32{receiver}, [][]byte{{byte(sender)}}, uint32(size), payloadLen)
+		if err := eM.SetColumn(e, int(sender), CreateVector([]uint32{receiver}, uint32(size)), payloads); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flip := func(eM *EpochMatrix) {
+		e, writes, err := eM.CloseRound()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := eM.PublishRound(e, writes, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(eM *EpochMatrix, e uint64, receiver uint32, senders []uint32) {
+		row, payloads, err := eM.GetRow(e, receiver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ReadVector(row), senders) {
+			t.Fatal("wrong senders in epoch", e, ReadVector(row))
+		}
+		for i, payload := range ReadPayloads(payloads, senders, payloadLen) {
+			if payload[0] != byte(senders[i]) {
+				t.Fatal("wrong payload in epoch", e)
+			}
+		}
+	}
+
+	eM := recover()
+	write(eM, 0, 3, 7)
+	flip(eM)
+	write(eM, 1, 4, 7)
+	// the open epoch is recovered as well, and a closed epoch stays closed
+	e, _, _ := eM.CloseRound()
+	eM.Close()
+
+	eM = recover()
+	if published, current, writes := eM.Published(); published != 1 || current != 2 || writes[3] != 1 {
+		t.Fatal("wrong recovered state", published, current, writes)
+	}
+	check(eM, 0, 7, []uint32{3})
+	if err := eM.PublishRound(e, map[uint32]uint32{4: 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	check(eM, 1, 7, []uint32{4})
+	eM.Close()
+
+	// a torn record at the end is dropped, also if only its checksum is wrong
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.Write([]byte{100, 0, 0, 0, 1, 2, 3})
+	f.Close()
+	eM = recover()
+	eM.Close()
+	f, _ = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.Write([]byte{3, 0, 0, 0, 9, 9, 9, 9, 1, 2, 3})
+	f.Close()
+	eM = recover()
+	write(eM, 2, 5, 7)
+	eM.Close()
+	eM = recover()
+	if _, current, _ := eM.Published(); current != 2 {
+		t.Fatal("wrong current epoch", current)
+	}
+	check(eM, 1, 7, []uint32{4})
+
+	// expired epochs are removed from the log
+	flip(eM)
+	before, _ := os.Stat(path)
+	flip(eM)
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() {
+		t.Fatal("log not compacted", before.Size(), after.Size())
+	}
+	eM.Close()
+	eM = recover()
+	check(eM, 2, 7, []uint32{5})
+	if _, _, err := eM.GetRow(1, 7); err == nil {
+		t.Fatal("expired epoch recovered")
+	}
+	eM.Close()
+
+	// a log of another matrix is kept and rejected
+	other := NewEpochMatrix(size+1, payloadLen, schedule)
+	if err := other.Persist(path); err == nil {
+		t.Fatal("log of another matrix accepted")
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(data, encodeHeader(size, payloadLen, false)) {
+		t.Fatal("log replaced")
+	}
+}
@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sabot/lib/database"
+	"sabot/lib/util"
+	"slices"
+)
+
+/*
+Authenticated notifications: every cell carries a MAC of its bit and its payload, a key and a tag
+of MacLen bytes each, where the tag is HMAC-SHA256 of the bit and the payload under the key
+(truncated to MacLen) and 0 for a cell without bit and payload.
+The sender of a column picks the keys of its cells and uploads shares of the keys and tags along
+with the shares of the bits and payloads. As the keys only exist in shares, a server that changes its
+share of a bit or a payload would have to compute the tag under the unknown key of the cell, so the
+receiver detects it (see VerifyRow) except with probability 2^-64.
+Cells nobody wrote have random key shares on every server, so notifications cannot be forged there either.
+The key shares of a column are derived from a seed per server, only the tags are uploaded.
+In the matrices the MAC follows the payload of a cell, i.e. a cell is payload | key | tag.
+The servers cannot check the MACs, so a wrong cell is either wrong on a server or was written
+wrongly by its sender. The receiver drops only the senders of wrong cells and keeps the rest of the row.
+MACs are not cheap: 2*MacLen = 16 bytes per cell are N^2 * 16 bytes per epoch of N rows,
+128 times the bit matrix, and a receiver downloads 16 bytes per column of its row.
+*/
+
+const MacLen = 8
+
+// returned (wrapped) if a reconstructed row does not match its MACs
+var ErrMac = errors.New("notification row failed authentication")
+
+// the key shares of a column derived from the seed of a server
+func macKeys(seed []byte, size int) []byte {
+	var key util.PRGKey
+	copy(key[:], seed)
+	keys := make([]byte, size*MacLen)
+	util.NewPRG(&key).Read(keys)
+	return keys
+}
+
+func randomSeeds(n int) [][]byte {
+	seeds := make([][]byte, n)
+	for i := range seeds {
+		seeds[i] = make([]byte, len(util.PRGKey{}))
+		if _, err := rand.Read(seeds[i]); err != nil {
+			panic(err)
+		}
+	}
+	return seeds
+}
+
+// the MAC keys of all rows of a column, given the seeds of all servers
+func columnKeys(seeds [][]byte, size int) []byte {
+	keys := make([]byte, size*MacLen)
+	for _, seed := range seeds {
+		database.XorInto(keys, macKeys(seed, size))
+	}
+	return keys
+}
+
+// the tag of a cell under its key, 0 for a cell without bit and payload so that cells nobody wrote verify
+func cellTag(key []byte, bit bool, payload []byte) []byte {
+	if !bit && !slices.ContainsFunc(payload, func(b byte) bool { return b != 0 }) {
+		return make([]byte, MacLen)
+	}
+	mac := hmac.New(sha256.New, key)
+	if bit {
+		mac.Write([]byte{1})
+	} else {
+		mac.Write([]byte{0})
+	}
+	mac.Write(payload)
+	return mac.Sum(nil)[:MacLen]
+}
+
+/*
+Generates the MACs of the (plain) column col and its (plain) payload column (nil for zero payloads),
+returns the seed and the share of the tag column of each server
+*/
+func GenMacs(col []byte, payloadCol []byte, size uint32, payloadLen int, numShares int) ([][]byte, [][]byte) {
+	seeds := randomSeeds(numShares)
+	keys := columnKeys(seeds, int(size))
+	tags := make([]byte, len(keys))
+	if payloadCol == nil {
+		payloadCol = make([]byte, int(size)*payloadLen)
+	}
+	for r := 0; r < int(size); r++ {
+		bit := col[r/8]&(0x80>>(r%8)) != 0
+		copy(tags[r*MacLen:], cellTag(keys[r*MacLen:(r+1)*MacLen], bit, payloadCol[r*payloadLen:(r+1)*payloadLen]))
+	}
+	return seeds, GenShares(tags, numShares)
+}
+
+// Like GenMacs for DPF notifications, payloads[i] is the payload of targets[i] (if any), the tags are sent as payload DPF keys (see GenPayloadKeys)
+func GenMacKeys(targets []uint32, payloads [][]byte, size uint32, payloadLen int, numKeys int) ([][]byte, [2][][]byte) {
+	seeds := randomSeeds(2)
+	keys := columnKeys(seeds, int(size))
+	tags := make([][]byte, len(targets))
+	for i, r := range targets {
+		payload := make([]byte, payloadLen)
+		if i < len(payloads) {
+			copy(payload, payloads[i])
+		}
+		tags[i] = cellTag(keys[r*MacLen:(r+1)*MacLen], true, payload)
+	}
+	return seeds, GenPayloadKeys(targets, tags, size, MacLen, numKeys)
+}
+
+// Returns the MAC column of a server (key | tag per row) from its seed and its share of the tags
+func MacColumn(seed []byte, tags []byte, size int) ([]byte, error) {
+	if len(seed) != len(util.PRGKey{}) || len(tags) != size*MacLen {
+		return nil, fmt.Errorf("MAC seed of length %d or tags of length %d", len(seed), len(tags))
+	}
+	keys := macKeys(seed, size)
+	col := make([]byte, 2*size*MacLen)
+	for r := 0; r < size; r++ {
+		copy(col[2*r*MacLen:], keys[r*MacLen:(r+1)*MacLen])
+		copy(col[(2*r+1)*MacLen:], tags[r*MacLen:(r+1)*MacLen])
+	}
+	return col, nil
+}
+
+// Joins the payload column (nil for no payloads) and the MAC column into the cells of a column
+func JoinCells(payloadCol []byte, macCol []byte, size int, payloadLen int) []byte {
+	cellLen := payloadLen + 2*MacLen
+	cells := make([]byte, size*cellLen)
+	for r := 0; r < size; r++ {
+		if payloadCol != nil {
+			copy(cells[r*cellLen:], payloadCol[r*payloadLen:(r+1)*payloadLen])
+		}
+		copy(cells[r*cellLen+payloadLen:(r+1)*cellLen], macCol[2*r*MacLen:])
+	}
+	return cells
+}
+
+// Splits a row of cells into its payload row (nil if payloadLen is 0) and its MAC row
+func SplitCells(row []byte, size int, payloadLen int) ([]byte, []byte) {
+	cellLen := payloadLen + 2*MacLen
+	var payloads []byte
+	if payloadLen > 0 {
+		payloads = make([]byte, size*payloadLen)
+	}
+	macs := make([]byte, 2*size*MacLen)
+	for c := 0; c < size; c++ {
+		copy(payloads[c*payloadLen:(c+1)*payloadLen], row[c*cellLen:])
+		copy(macs[2*c*MacLen:2*(c+1)*MacLen], row[c*cellLen+payloadLen:])
+	}
+	return payloads, macs
+}
+
+/*
+Checks a reconstructed row and its reconstructed payload row (nil if payloadLen is 0) against the
+reconstructed MAC row, returns the senders whose cells do not match and an error if there are any.
+The bits and payloads of the other senders are authentic.
+Rows of the wrong length are an error without senders.
+*/
+func VerifyRow(row []byte, payloads []byte, macs []byte, payloadLen int) ([]uint32, error) {
+	size := len(macs) / (2 * MacLen)
+	if len(macs) != 2*size*MacLen || len(row) != (size+7)/8 || len(payloads) != size*payloadLen {
+		return nil, fmt.Errorf("%w: MAC row of length %d for row of length %d and payload row of length %d", ErrMac, len(macs), len(row), len(payloads))
+	}
+	var failed []uint32
+	for c := 0; c < size; c++ {
+		key, tag := macs[2*c*MacLen:(2*c+1)*MacLen], macs[(2*c+1)*MacLen:2*(c+1)*MacLen]
+		want := cellTag(key, row[c/8]&(0x80>>(c%8)) != 0, payloads[c*payloadLen:(c+1)*payloadLen])
+		if !bytes.Equal(tag, want) {
+			failed = append(failed, uint32(c))
+		}
+	}
+	if len(failed) > 0 {
+		return failed, fmt.Errorf("%w: cells of senders %v", ErrMac, failed)
+	}
+	return nil, nil
+}
+
+// fills the keys of the cells (payload | key | tag) of a payload matrix with fresh random shares, in column cIdx only if cIdx >= 0
+func randomizeKeys(cells []byte, size int, payloadLen int, cIdx int) {
+	cellLen := payloadLen + 2*MacLen
+	prg := util.RandomPRG()
+	keys := make([]byte, size*MacLen)
+	for r := 0; r < len(cells)/(size*cellLen); r++ {
+		prg.Read(keys)
+		for c := 0; c < size; c++ {
+			if cIdx < 0 || c == cIdx {
+				off := (r*size+c)*cellLen + payloadLen
+				copy(cells[off:off+MacLen], keys[c*MacLen:])
+			}
+		}
+	}
+}
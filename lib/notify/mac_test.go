@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMacs(t *testing.T) {
+	size := 20
+	payloadLen := 4
+	sender, receiver := 3, uint32(7)
+	servers := make([]*EpochMatrix, 2)
+	for i := range servers {
+		servers[i] = NewEpochMatrix(size, payloadLen, Schedule{})
+		servers[i].Macs = true
+	}
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	if err := servers[0].Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	// payloadCol holds the payload shares of the servers, nil for no payloads
+	write := func(cIdx int, col [][]byte, payloadCol [][]byte, seeds [][]byte, tags [][]byte) {
+		for i, eM := range servers {
+			macCol, err := MacColumn(seeds[i], tags[i], size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var payloads []byte
+			if payloadCol != nil {
+				payloads = payloadCol[i]
+			}
+			if err := eM.SetColumn(0, cIdx, col[i], JoinCells(payloads, macCol, size, payloadLen)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// returns the authentic senders of the receiver, the senders that failed the MAC check and its error,
+	// shares[i] replaces the row share of server i, tamper changes the cells of the second server
+	var tamper func(cells []byte)
+	read := func(shares ...[]byte) ([]uint32, []uint32, error) {
+		rows := [][]byte{nil, nil}
+		payloads := [][]byte{nil, nil}
+		macs := [][]byte{nil, nil}
+		for i, eM := range servers {
+			row, cells, err := eM.GetRow(0, receiver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows[i] = row
+			if i < len(shares) && shares[i] != nil {
+				rows[i] = shares[i]
+			}
+			if i == 1 && tamper != nil {
+				cells = slices.Clone(cells)
+				tamper(cells)
+			}
+			payloads[i], macs[i] = SplitCells(cells, size, payloadLen)
+		}
+		row := CombineShares(rows)
+		failed, err := VerifyRow(row, CombineShares(payloads), CombineShares(macs), payloadLen)
+		// only the senders of wrong cells fail
+		for _, c := range failed {
+			row[c/8] &^= 0x80 >> (c % 8)
+		}
+		return ReadVector(row), failed, err
+	}
+
+	if senders, _, err := read(); err != nil || len(senders) != 0 {
+		t.Fatal("empty row rejected", senders, err)
+	}
+	col := CreateVector([]uint32{receiver, 9}, uint32(size))
+	payloadCol := CreatePayloadColumn([]uint32{receiver}, [][]byte{{1, 2, 3, 4}}, uint32(size), payloadLen)
+	seeds, tags := GenMacs(col, payloadCol, uint32(size), payloadLen, 2)
+	write(sender, GenShares(col, 2), GenShares(payloadCol, 2), seeds, tags)
+	if senders, _, err := read(); err != nil || !slices.Equal(senders, []uint32{uint32(sender)}) {
+		t.Fatal("authentic row rejected", senders, err)
+	}
+
+	// a server drops the notification or adds one of a sender that did not write, the other cells are kept
+	col = CreateVector([]uint32{receiver}, uint32(size))
+	seeds, tags = GenMacs(col, nil, uint32(size), payloadLen, 2)
+	write(4, GenShares(col, 2), nil, seeds, tags)
+	for _, c := range []int{sender, 11} {
+		share, _, _ := servers[1].GetRow(0, receiver)
+		share[c/8] ^= 0x80 >> (c % 8)
+		senders, failed, err := read(nil, share)
+		if !errors.Is(err, ErrMac) || !slices.Equal(failed, []uint32{uint32(c)}) {
+			t.Fatal("tampered cell of sender", c, "accepted", failed, err)
+		}
+		if want := slices.DeleteFunc([]uint32{3, 4}, func(s uint32) bool { return s == uint32(c) }); !slices.Equal(senders, want) {
+			t.Fatal("authentic cells dropped", senders)
+		}
+	}
+
+	// a server that changes a payload is detected as well
+	tamper = func(cells []byte) { cells[sender*(payloadLen+2*MacLen)+1] ^= 1 }
+	if senders, failed, err := read(); !errors.Is(err, ErrMac) || !slices.Equal(failed, []uint32{uint32(sender)}) || !slices.Equal(senders, []uint32{4}) {
+		t.Fatal("tampered payload accepted", senders, failed, err)
+	}
+	tamper = func(cells []byte) { cells[11*(payloadLen+2*MacLen)] ^= 1 }
+	if _, failed, err := read(); !errors.Is(err, ErrMac) || !slices.Equal(failed, []uint32{11}) {
+		t.Fatal("payload added to an empty cell accepted", failed, err)
+	}
+	tamper = nil
+
+	// DPF notifications authenticate the same way
+	targets := []uint32{receiver}
+	payloads := [][]byte{{5, 6}}
+	seeds, macKeys := GenMacKeys(targets, payloads, uint32(size), payloadLen, 2)
+	tags = make([][]byte, 2)
+	for i := range tags {
+		var err error
+		if tags[i], err = ExpandPayloadKeys(macKeys[i], size, MacLen); err != nil {
+			t.Fatal(err)
+		}
+	}
+	col = CreateVector(targets, uint32(size))
+	write(5, GenShares(col, 2), GenShares(CreatePayloadColumn(targets, payloads, uint32(size), payloadLen), 2), seeds, tags)
+	if senders, _, err := read(); err != nil || !slices.Equal(senders, []uint32{3, 4, 5}) {
+		t.Fatal("authentic DPF notification rejected", senders, err)
+	}
+
+	// the MACs are recovered from the log
+	servers[0].Close()
+	servers[0] = NewEpochMatrix(size, payloadLen, Schedule{})
+	servers[0].Macs = true
+	if err := servers[0].Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	if senders, _, err := read(); err != nil || !slices.Equal(senders, []uint32{3, 4, 5}) {
+		t.Fatal("recovered row rejected", senders, err)
+	}
+
+	if _, err := MacColumn(seeds[0], tags[0][1:], size); err == nil {
+		t.Fatal("expected error for short tags")
+	}
+}
@@ -1,41 +1,64 @@
 package notify
 
 import (
-	"sabot/lib/database"
+	"encoding/binary"
 )
 
+/*
+The matrix is stored as packed bits, row-major in 64-bit words,
+so it needs size*size/8 bytes instead of one byte per relation.
+Bit c of a row is bit 63-c%64 of word c/64, i.e. the words are the big-endian
+reading of the notification vectors (see CreateVector), where the first bit of a byte is its MSB.
+*/
 type NotifyMatrix struct {
-	*database.StaticDB
+	NumRows     int
+	wordsPerRow int
+	words       []uint64
 }
 
+// Replaces column cIdx with col, where bit r of col is the entry of row r.
+// Rows beyond the length of col are cleared.
 func (nM *NotifyMatrix) SetColumn(cIdx int, col []byte) {
-	// need to transform col (where each bit indicates a relation) to a matrix column where each
-	// byte indicates a relation. Bytes are the smalles unit in go, so getting the row should be easiest this way
-	rIndices := ReadVector(col)
+	w := cIdx / 64
+	mask := uint64(1) << (63 - cIdx%64)
+	for r := 0; r < nM.NumRows; r++ {
+		word := &nM.words[r*nM.wordsPerRow+w]
+		if r/8 < len(col) && (col[r/8]>>(7-r%8))&1 == 1 {
+			*word |= mask
+		} else {
+			*word &^= mask
+		}
+	}
+}
 
-	for _, rIdx := range rIndices {
-		nM.FlatDb[int(rIdx)*nM.RowLen+cIdx] = byte(1)
+// Sets all entries of column cIdx to 0
+func (nM *NotifyMatrix) ClearColumn(cIdx int) {
+	w := cIdx / 64
+	mask := uint64(1) << (63 - cIdx%64)
+	for r := 0; r < nM.NumRows; r++ {
+		nM.words[r*nM.wordsPerRow+w] &^= mask
 	}
 }
 
 // returns row in compressed byte array, where one bit represents a relation
 func (nM *NotifyMatrix) GetRow(rIdx uint32) []byte {
 	if rIdx < uint32(nM.NumRows) {
-		matrixRow := nM.Row(int(rIdx))
-		return VecFromMatrixSlice(matrixRow)
+		words := nM.words[int(rIdx)*nM.wordsPerRow : (int(rIdx)+1)*nM.wordsPerRow]
+		row := make([]byte, 8*len(words))
+		for i, word := range words {
+			binary.BigEndian.PutUint64(row[8*i:], word)
+		}
+		return row[:(nM.NumRows+7)/8]
 	}
 	return nil
 }
 
 func NewMatrix(size int) *NotifyMatrix {
-	nM := NotifyMatrix{}
-	nM.StaticDB = &database.StaticDB{}
-	nM.RowLen = size
-	nM.NumRows = size
-	nM.FlatDb = make([]byte, size*size)
+	nM := NotifyMatrix{NumRows: size, wordsPerRow: (size + 63) / 64}
+	nM.words = make([]uint64, size*nM.wordsPerRow)
 	// ensures Matrix is properly initialized and actually using memory
-	for i := range nM.FlatDb {
-		nM.FlatDb[i] = 0
+	for i := range nM.words {
+		nM.words[i] = 0
 	}
 	return &nM
 }
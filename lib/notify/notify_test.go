@@ -77,3 +77,102 @@ func TestBuildMatrix(t *testing.T) {
 		}
 	}
 }
+
+func TestMatrixRows(t *testing.T) {
+	for _, size := range []int{1, 63, 64, 65, 130} {
+		nM := NewMatrix(size)
+		// column i notifies the receivers i and size-1-i, so the matrix is symmetric
+		targets := func(i int) []uint32 {
+			if i == size-1-i {
+				return []uint32{uint32(i)}
+			}
+			return []uint32{uint32(i), uint32(size - 1 - i)}
+		}
+		for c := 0; c < size; c++ {
+			nM.SetColumn(c, CreateVector(targets(c), uint32(size)))
+		}
+		for r := 0; r < size; r++ {
+			row := nM.GetRow(uint32(r))
+			if len(row) != (size+7)/8 {
+				t.Fatalf("row has length %d, expected %d", len(row), (size+7)/8)
+			}
+			expected := CreateVector(targets(r), uint32(size))
+			if !slices.Equal(row, expected) {
+				t.Fatalf("wrong row %d for size %d", r, size)
+			}
+		}
+		if nM.GetRow(uint32(size)) != nil {
+			t.Fatal("expected nil for row out of range")
+		}
+	}
+}
+
+// Two servers hold XOR shares of the matrix, a sender notifies different receivers in every round
+func TestRepeatedNotifications(t *testing.T) {
+	size := 70
+	sender := 5
+	matrices := []*NotifyMatrix{NewMatrix(size), NewMatrix(size)}
+	reconstruct := func(receiver int) bool {
+		row := CombineShares([][]byte{matrices[0].GetRow(uint32(receiver)), matrices[1].GetRow(uint32(receiver))})
+		return slices.Contains(ReadVector(row), uint32(sender))
+	}
+
+	rounds := [][]uint32{{1, 2, 69}, {2, 3}, {}, {0, 69}}
+	for round, targets := range rounds {
+		shares := GenShares(CreateVector(targets, uint32(size)), 2)
+		for i, m := range matrices {
+			m.SetColumn(sender, shares[i])
+		}
+		for receiver := 0; receiver < size; receiver++ {
+			if reconstruct(receiver) != slices.Contains(targets, uint32(receiver)) {
+				t.Fatalf("wrong notification for receiver %d in round %d", receiver, round)
+			}
+		}
+	}
+
+	for _, m := range matrices {
+		m.ClearColumn(sender)
+	}
+	for receiver := 0; receiver < size; receiver++ {
+		if reconstruct(receiver) {
+			t.Fatalf("receiver %d still notified after clearing the column", receiver)
+		}
+		for _, m := range matrices {
+			if len(ReadVector(m.GetRow(uint32(receiver)))) != 0 {
+				t.Fatal("cleared matrix is not empty")
+			}
+		}
+	}
+}
+
+func TestPayloadNotifications(t *testing.T) {
+	size := 20
+	payloadLen := 32
+	matrices := []*PayloadMatrix{NewPayloadMatrix(size, payloadLen), NewPayloadMatrix(size, payloadLen)}
+	for sender := 0; sender < size; sender++ {
+		targets := []uint32{uint32((sender + 1) % size), uint32((sender + 3) % size)}
+		payloads := [][]byte{{byte(sender), 1}, {byte(sender), 3}}
+		shares := GenShares(CreatePayloadColumn(targets, payloads, uint32(size), payloadLen), 2)
+		for i, m := range matrices {
+			m.SetColumn(sender, shares[i])
+		}
+	}
+	for receiver := 0; receiver < size; receiver++ {
+		row := CombineShares([][]byte{matrices[0].GetRow(uint32(receiver)), matrices[1].GetRow(uint32(receiver))})
+		senders := []uint32{uint32((receiver + size - 1) % size), uint32((receiver + size - 3) % size), uint32((receiver + size - 2) % size)}
+		payloads := ReadPayloads(row, senders, payloadLen)
+		expected := [][]byte{{byte(senders[0]), 1}, {byte(senders[1]), 3}, {}}
+		for i := range payloads {
+			if !slices.Equal(payloads[i], append(expected[i], make([]byte, payloadLen-len(expected[i]))...)) {
+				t.Fatalf("wrong payload from sender %d for receiver %d", senders[i], receiver)
+			}
+		}
+	}
+
+	matrices[0].ClearColumn(3)
+	matrices[1].ClearColumn(3)
+	row := CombineShares([][]byte{matrices[0].GetRow(4), matrices[1].GetRow(4)})
+	if !slices.Equal(ReadPayloads(row, []uint32{3}, payloadLen)[0], make([]byte, payloadLen)) {
+		t.Fatal("payload not cleared")
+	}
+}
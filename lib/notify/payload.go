@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"log"
+)
+
+/*
+Optional second matrix next to the NotifyMatrix, where every cell holds a share of a
+fixed-size payload (e.g. an encrypted handshake message) instead of a single bit.
+Cell (r, c) is the payload sender c left for receiver r, cells are stored row-major,
+so a row is a contiguous slice of NumRows*PayloadLen bytes.
+*/
+type PayloadMatrix struct {
+	NumRows    int
+	PayloadLen int
+	cells      []byte
+}
+
+func NewPayloadMatrix(size int, payloadLen int) *PayloadMatrix {
+	return &PayloadMatrix{size, payloadLen, make([]byte, size*size*payloadLen)}
+}
+
+// Replaces column cIdx with col, which holds the payloads of all rows one after another
+func (pM *PayloadMatrix) SetColumn(cIdx int, col []byte) {
+	if len(col) != pM.NumRows*pM.PayloadLen {
+		log.Fatal("payload column has wrong length ", len(col))
+	}
+	for r := 0; r < pM.NumRows; r++ {
+		copy(pM.cells[(r*pM.NumRows+cIdx)*pM.PayloadLen:(r*pM.NumRows+cIdx+1)*pM.PayloadLen], col[r*pM.PayloadLen:(r+1)*pM.PayloadLen])
+	}
+}
+
+// Sets all payloads of column cIdx to 0
+func (pM *PayloadMatrix) ClearColumn(cIdx int) {
+	for r := 0; r < pM.NumRows; r++ {
+		clear(pM.cells[(r*pM.NumRows+cIdx)*pM.PayloadLen : (r*pM.NumRows+cIdx+1)*pM.PayloadLen])
+	}
+}
+
+// returns a copy of the payloads of row rIdx, the payload of sender c is at c*PayloadLen
+func (pM *PayloadMatrix) GetRow(rIdx uint32) []byte {
+	if rIdx < uint32(pM.NumRows) {
+		rowLen := pM.NumRows * pM.PayloadLen
+		row := make([]byte, rowLen)
+		copy(row, pM.cells[int(rIdx)*rowLen:(int(rIdx)+1)*rowLen])
+		return row
+	}
+	return nil
+}
+
+/*
+Creates a payload column
+size: number of rows
+targets: list of receivers, payloads[i] is placed in the row of targets[i]
+payloads shorter than payloadLen are padded with zeros, all other rows are 0
+*/
+func CreatePayloadColumn(targets []uint32, payloads [][]byte, size uint32, payloadLen int) []byte {
+	col := make([]byte, int(size)*payloadLen)
+	for i, target := range targets {
+		if target >= size || len(payloads[i]) > payloadLen {
+			log.Fatal("payload for target ", target, " could not be added")
+		}
+		copy(col[int(target)*payloadLen:], payloads[i])
+	}
+	return col
+}
+
+// returns the payloads of the given senders from a (reconstructed) payload row
+func ReadPayloads(row []byte, senders []uint32, payloadLen int) [][]byte {
+	payloads := make([][]byte, len(senders))
+	for i, sender := range senders {
+		payloads[i] = row[int(sender)*payloadLen : (int(sender)+1)*payloadLen]
+	}
+	return payloads
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sabot/lib/util"
+	"slices"
+)
+
+/*
+Proactive resharing of the stored shares: whenever an epoch is published, both servers XOR the
+same pseudorandom mask into the matrices of all epochs that are no longer written, which leaves
+the reconstructed rows unchanged but makes shares taken from one server before the publication
+useless together with shares taken from the other server after it.
+The mask is derived from a key the servers share, which is ratcheted forward with every
+publication and the old key is erased, so a server compromised later cannot recompute old masks.
+The ratchet also takes fresh randomness of both servers, which each server draws when it closes
+an epoch and only gives to the other server (see ReshareNonce), so a key taken from a server does
+not give the masks of later publications without their randomness.
+Both servers have to start with the same key and publish the same epochs (see FlipRound).
+*/
+
+const (
+	reshareKeyLen   = sha256.Size
+	reshareNonceLen = 16
+)
+
+func reshareHash(key []byte, label string, e uint64) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	mac.Write(binary.LittleEndian.AppendUint64(nil, e))
+	return mac.Sum(nil)
+}
+
+/*
+EnableResharing starts resharing with the key shared by the servers (at least 16 bytes),
+the caller should erase the key afterwards.
+Has to be called before Persist: a log that already reshares keeps its own, ratcheted key.
+*/
+func (eM *EpochMatrix) EnableResharing(key []byte) {
+	eM.mu.Lock()
+	defer eM.mu.Unlock()
+	// the key is only kept in its derived form, so it can be erased
+	eM.reshareKey = reshareHash(key, "sabot reshare key", 0)
+}
+
+// returns a tag of the current resharing key (nil if resharing is disabled), servers with the same tag reshare alike
+func (eM *EpochMatrix) ReshareTag() []byte {
+	eM.mu.RLock()
+	defer eM.mu.RUnlock()
+	if eM.reshareKey == nil {
+		return nil
+	}
+	return reshareHash(eM.reshareKey, "sabot reshare tag", eM.published)[:8]
+}
+
+/*
+ReshareNonce returns the randomness this server contributes to the publication of epoch e, which is
+drawn when the epoch is closed and kept until the next epoch is published (for a peer that missed the publication).
+It must only be given to the other server, PublishRound takes the randomness of both servers.
+*/
+func (eM *EpochMatrix) ReshareNonce(e uint64) ([]byte, error) {
+	eM.mu.RLock()
+	defer eM.mu.RUnlock()
+	nonce, ok := eM.reshareNonces[e]
+	if !ok {
+		return nil, fmt.Errorf("%w: no resharing randomness for epoch %d", ErrEpochPending, e)
+	}
+	return slices.Clone(nonce), nil
+}
+
+func newReshareNonce() []byte {
+	nonce := make([]byte, reshareNonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return nonce
+}
+
+// ratchets the key with the randomness of the publication and masks all epochs before the current one, called when an epoch is published
+func (eM *EpochMatrix) reshare(nonce []byte) {
+	mac := hmac.New(sha256.New, eM.reshareKey)
+	mac.Write([]byte("sabot reshare ratchet"))
+	mac.Write(binary.LittleEndian.AppendUint64(nil, eM.published))
+	mac.Write(nonce)
+	clear(eM.reshareKey)
+	eM.reshareKey = mac.Sum(nil)
+
+	for e := eM.oldest(); e < eM.current; e++ {
+		m := eM.epochs[e]
+		// an epoch without writes has all-zero shares on both servers, it is masked as well
+		if m == nil {
+			m = eM.newEpoch()
+			eM.epochs[e] = m
+		}
+		var key util.PRGKey
+		copy(key[:], reshareHash(eM.reshareKey, "sabot reshare mask", e))
+		mask := make([]byte, 8*len(m.bits.words))
+		if m.payloads != nil {
+			mask = make([]byte, 8*len(m.bits.words)+len(m.payloads.cells))
+		}
+		util.NewPRG(&key).Read(mask)
+		for i := range m.bits.words {
+			m.bits.words[i] ^= binary.LittleEndian.Uint64(mask[8*i:])
+		}
+		if m.payloads != nil {
+			for i, b := range mask[8*len(m.bits.words):] {
+				m.payloads.cells[i] ^= b
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReshare(t *testing.T) {
+	size := 30
+	payloadLen := 4
+	key := []byte("0123456789abcdef")
+	schedule := Schedule{Length: time.Hour, Retention: 3}
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	servers := make([]*EpochMatrix, 2)
+	for i := range servers {
+		servers[i] = NewEpochMatrix(size, payloadLen, schedule)
+		servers[i].EnableResharing(key)
+	}
+	if err := servers[0].Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	// both servers publish with the randomness both of them drew when closing the epoch
+	flip := func() {
+		var e uint64
+		var nonce []byte
+		writes := make([]map[uint32]uint32, len(servers))
+		for i, eM := range servers {
+			var err error
+			if e, writes[i], err = eM.CloseRound(); err != nil {
+				t.Fatal(err)
+			}
+			own, err := eM.ReshareNonce(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nonce = append(nonce, own...)
+		}
+		for i, eM := range servers {
+			if err := eM.PublishRound(e, writes[i], nonce); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	row := func(e uint64, receiver uint32, shares ...[]byte) ([]uint32, []byte) {
+		rows := [][]byte{nil, nil}
+		payloads := [][]byte{nil, nil}
+		for i, eM := range servers {
+			var err error
+			if rows[i], payloads[i], err = eM.GetRow(e, receiver); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(shares) > 0 {
+			rows[0] = shares[0]
+		}
+		senders := ReadVector(CombineShares(rows))
+		return senders, CombineShares(payloads)
+	}
+
+	receiver := uint32(7)
+	col := GenShares(CreateVector([]uint32{receiver}, uint32(size)), 2)
+	payloadCol := GenShares(CreatePayloadColumn([]uint32{receiver}, [][]byte{{9}}, uint32(size), payloadLen), 2)
+	for i, eM := range servers {
+		if err := eM.SetColumn(0, 3, col[i], payloadCol[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(servers[0].ReshareTag(), servers[1].ReshareTag()) {
+		t.Fatal("servers with the same key have different tags")
+	}
+	flip()
+	old, _, _ := servers[0].GetRow(0, receiver)
+	oldKey := slices.Clone(servers[0].reshareKey)
+	flip()
+
+	// the rows are unchanged, but the shares are not
+	senders, payloads := row(0, receiver)
+	if !slices.Equal(senders, []uint32{3}) || ReadPayloads(payloads, senders, payloadLen)[0][0] != 9 {
+		t.Fatal("resharing changed the notifications", senders)
+	}
+	if share, _, _ := servers[0].GetRow(0, receiver); bytes.Equal(share, old) {
+		t.Fatal("shares not reshared")
+	}
+	if senders, _ := row(0, receiver, old); slices.Equal(senders, []uint32{3}) {
+		t.Fatal("old share combines with the new share")
+	}
+	// the epoch without writes is reshared as well
+	if senders, _ := row(1, receiver); len(senders) != 0 {
+		t.Fatal("empty epoch not reshared alike", senders)
+	}
+
+	// the log holds the ratcheted key only, and it replaces the key given to a recovered matrix
+	last, _, _ := servers[0].Published()
+	nonce, err := servers[0].ReshareNonce(last - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	servers[0].Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, oldKey) || !bytes.Contains(data, servers[0].reshareKey) {
+		t.Fatal("log does not hold the current key only")
+	}
+	servers[0] = NewEpochMatrix(size, payloadLen, schedule)
+	servers[0].EnableResharing([]byte("another key 0123"))
+	if err := servers[0].Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(servers[0].ReshareTag(), servers[1].ReshareTag()) {
+		t.Fatal("recovered server has another key")
+	}
+	// the randomness of the last publication is kept for a peer that missed it, older randomness is erased
+	if recovered, err := servers[0].ReshareNonce(last - 1); err != nil || !bytes.Equal(recovered, nonce) {
+		t.Fatal("randomness of the last publication not recovered", err)
+	}
+	if _, err := servers[0].ReshareNonce(last - 2); err == nil {
+		t.Fatal("randomness of an older publication kept")
+	}
+	flip()
+	if senders, _ := row(0, receiver); !slices.Equal(senders, []uint32{3}) {
+		t.Fatal("recovered server reshared differently", senders)
+	}
+
+	// a failed compaction after resharing is reported, no epoch is closed until the log is compacted
+	if err := os.Mkdir(path+".tmp", 0700); err != nil {
+		t.Fatal(err)
+	}
+	e, writes, _ := servers[0].CloseRound()
+	if err := servers[0].PublishRound(e, writes, nil); err == nil {
+		t.Fatal("failed compaction not reported")
+	}
+	if _, _, err := servers[0].CloseRound(); err == nil {
+		t.Fatal("epoch closed before the log was compacted")
+	}
+	os.Remove(path + ".tmp")
+	if _, _, err := servers[0].CloseRound(); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := [2]*EpochMatrix{NewEpochMatrix(size, payloadLen, schedule), NewEpochMatrix(size, payloadLen, schedule)}
+	fresh[0].EnableResharing(key)
+	fresh[1].EnableResharing([]byte("another key 0123"))
+	if bytes.Equal(fresh[0].ReshareTag(), fresh[1].ReshareTag()) {
+		t.Fatal("different keys have the same tag")
+	}
+
+	// the key is ratcheted with the randomness of the publication, the same key with other randomness reshares differently
+	fresh[1] = NewEpochMatrix(size, payloadLen, schedule)
+	fresh[1].EnableResharing(key)
+	for i, eM := range fresh {
+		e, writes, _ := eM.CloseRound()
+		if err := eM.PublishRound(e, writes, randomSeeds(1)[0]); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 && bytes.Equal(fresh[0].ReshareTag(), fresh[1].ReshareTag()) {
+			t.Fatal("different randomness gives the same key")
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sabot/lib/util"
+)
+
+/*
+Sketch-based verification of column writes against malicious clients (in the style of the
+Riposte/Express audits). In verified mode a client uploads its column as parts, the share of
+one target each (or of an empty dummy part), so a column has at most one bit set per part.
+The servers check that every reconstructed part has at most one bit set without learning it:
+
+For random r, s in GF(2^64)^N (derived from a key shared by the servers, unknown to the client)
+a part v with support S has the linear sketches A = sum r_i, B = sum s_i and C = sum r_i*s_i over S,
+so every server can compute its share of them from its share of v.
+If |S| <= 1 then A*B = C, otherwise A*B + C = sum_{i != j} r_i*s_j, which is 0 (or any value chosen
+by the client) with probability at most 2/2^64. The product A*B is computed with a multiplication
+triple the client shares with its write, a wrong triple only shifts A*B + C by a constant the client
+fixed before the sketch keys were known.
+Each server sends two field elements per part in the first round and one in the second.
+*/
+
+// multiplication in GF(2^64) with the reduction polynomial x^64 + x^4 + x^3 + x + 1
+func gfMul(a, b uint64) uint64 {
+	var hi, lo uint64
+	for i := 0; i < 64; i++ {
+		if (b>>i)&1 == 1 {
+			lo ^= a << i
+			if i > 0 {
+				hi ^= a >> (64 - i)
+			}
+		}
+	}
+	// x^64 = x^4 + x^3 + x + 1, reduce hi and the bits it overflows into
+	over := hi>>63 ^ hi>>61 ^ hi>>60
+	return lo ^ hi ^ hi<<1 ^ hi<<3 ^ hi<<4 ^ over ^ over<<1 ^ over<<3 ^ over<<4
+}
+
+// one server's shares of a multiplication triple (alpha, beta, alpha*beta)
+type Triple struct {
+	Alpha, Beta, Gamma uint64
+}
+
+const tripleLen = 24
+
+func readUint64(r io.Reader) uint64 {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		panic("notify: failed to sample triples")
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// Generates a multiplication triple per part, returns the triples of each of the two servers
+func GenTriples(numParts int) [2][]Triple {
+	r := util.RandomPRG()
+	var out [2][]Triple
+	for i := 0; i < numParts; i++ {
+		alpha, beta := readUint64(r), readUint64(r)
+		t0 := Triple{readUint64(r), readUint64(r), readUint64(r)}
+		out[0] = append(out[0], t0)
+		out[1] = append(out[1], Triple{alpha ^ t0.Alpha, beta ^ t0.Beta, gfMul(alpha, beta) ^ t0.Gamma})
+	}
+	return out
+}
+
+func EncodeTriples(triples []Triple) []byte {
+	out := make([]byte, 0, tripleLen*len(triples))
+	for _, t := range triples {
+		out = binary.LittleEndian.AppendUint64(out, t.Alpha)
+		out = binary.LittleEndian.AppendUint64(out, t.Beta)
+		out = binary.LittleEndian.AppendUint64(out, t.Gamma)
+	}
+	return out
+}
+
+func DecodeTriples(data []byte, numParts int) ([]Triple, error) {
+	if len(data) != tripleLen*numParts {
+		return nil, fmt.Errorf("got %d bytes of triples for %d parts", len(data), numParts)
+	}
+	triples := make([]Triple, numParts)
+	for i := range triples {
+		triples[i].Alpha = binary.LittleEndian.Uint64(data[tripleLen*i:])
+		triples[i].Beta = binary.LittleEndian.Uint64(data[tripleLen*i+8:])
+		triples[i].Gamma = binary.LittleEndian.Uint64(data[tripleLen*i+16:])
+	}
+	return triples, nil
+}
+
+/*
+Splits the notification vector of targets into numParts vectors with one target each,
+the remaining parts are empty. The XOR of the parts is the notification vector.
+*/
+func SplitColumn(targets []uint32, size uint32, numParts int) [][]byte {
+	if len(targets) > numParts {
+		panic("notify: more targets than parts")
+	}
+	parts := make([][]byte, numParts)
+	for i := range parts {
+		if i < len(targets) {
+			parts[i] = CreateVector(targets[i:i+1], size)
+		} else {
+			parts[i] = CreateVector(nil, size)
+		}
+	}
+	return parts
+}
+
+/*
+Derives the sketch key of a write from the key shared by the servers, the id of the write
+and its column and epoch, so a verification only holds for the column and epoch it was run for.
+*/
+func SketchKey(serverKey []byte, writeID []byte, idx uint32, epoch uint64) *util.PRGKey {
+	mac := hmac.New(sha256.New, serverKey)
+	mac.Write([]byte("sabot sketch"))
+	mac.Write(binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint32(nil, idx), epoch))
+	mac.Write(writeID)
+	var key util.PRGKey
+	copy(key[:], mac.Sum(nil))
+	return &key
+}
+
+// Verification of the parts of one write by one server
+type SketchVerifier struct {
+	server  int // 0 or 1
+	triples []Triple
+	a, b, c []uint64 // shares of the sketches of every part
+}
+
+/*
+Computes the sketch shares of the parts, key is the sketch key of the write (see SketchKey),
+which has to be the same on both servers. All parts have to be vectors of numRows bits.
+*/
+func NewSketchVerifier(server int, numRows int, key *util.PRGKey, parts [][]byte, triples []Triple) (*SketchVerifier, error) {
+	if len(triples) != len(parts) {
+		return nil, fmt.Errorf("got %d triples for %d parts", len(triples), len(parts))
+	}
+	for _, part := range parts {
+		if len(part) != (numRows+7)/8 {
+			return nil, fmt.Errorf("part has length %d", len(part))
+		}
+	}
+	v := &SketchVerifier{server: server, triples: triples,
+		a: make([]uint64, len(parts)), b: make([]uint64, len(parts)), c: make([]uint64, len(parts))}
+	prg := util.NewBufPRG(util.NewPRG(key))
+	for i := 0; i < numRows; i++ {
+		r, s := prg.Uint64(), prg.Uint64()
+		var rs uint64
+		computed := false
+		for p, part := range parts {
+			if (part[i/8]>>(7-i%8))&1 == 0 {
+				continue
+			}
+			if !computed {
+				rs, computed = gfMul(r, s), true
+			}
+			v.a[p] ^= r
+			v.b[p] ^= s
+			v.c[p] ^= rs
+		}
+	}
+	return v, nil
+}
+
+// returns the first message to the other server, the masked sketches d, e of every part
+func (v *SketchVerifier) Round1() []uint64 {
+	out := make([]uint64, 0, 2*len(v.triples))
+	for p, t := range v.triples {
+		out = append(out, v.a[p]^t.Alpha, v.b[p]^t.Beta)
+	}
+	return out
+}
+
+// takes the first message of the other server, returns the second message
+func (v *SketchVerifier) Round2(peer []uint64) ([]uint64, error) {
+	if len(peer) != 2*len(v.triples) {
+		return nil, fmt.Errorf("got %d values in round 1, expected %d", len(peer), 2*len(v.triples))
+	}
+	own := v.Round1()
+	out := make([]uint64, len(v.triples))
+	for p, t := range v.triples {
+		d, e := own[2*p]^peer[2*p], own[2*p+1]^peer[2*p+1]
+		// shares of A*B + C, the shares of both servers are equal iff A*B = C
+		out[p] = gfMul(d, t.Beta) ^ gfMul(e, t.Alpha) ^ t.Gamma ^ v.c[p]
+		if v.server == 0 {
+			out[p] ^= gfMul(d, e)
+		}
+	}
+	return out, nil
+}
+
+// takes the second messages of both servers, returns whether every part has at most one bit set
+func (v *SketchVerifier) Verify(own, peer []uint64) bool {
+	if len(own) != len(v.triples) || len(peer) != len(v.triples) {
+		return false
+	}
+	for p := range own {
+		if own[p] != peer[p] {
+			return false
+		}
+	}
+	return true
+}
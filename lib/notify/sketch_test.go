@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"sabot/lib/util"
+	"testing"
+)
+
+func TestGFMul(t *testing.T) {
+	// x^63 * x = x^64 = x^4 + x^3 + x + 1
+	if gfMul(1<<63, 2) != 0x1b {
+		t.Fatal("wrong reduction")
+	}
+	r := util.NewBufPRG(util.RandomPRG())
+	for i := 0; i < 100; i++ {
+		a, b, c := r.Uint64(), r.Uint64(), r.Uint64()
+		if gfMul(a, b) != gfMul(b, a) || gfMul(a, 1) != a {
+			t.Fatal("multiplication not commutative")
+		}
+		if gfMul(gfMul(a, b), c) != gfMul(a, gfMul(b, c)) || gfMul(a, b^c) != gfMul(a, b)^gfMul(a, c) {
+			t.Fatal("multiplication not associative or distributive")
+		}
+	}
+}
+
+// runs the verification of both servers on the shares of parts
+func verifyParts(t *testing.T, size int, parts [][]byte, triples [2][]Triple) bool {
+	key := SketchKey([]byte("0123456789abcdef"), []byte("write"), 3, 0)
+	shares := [2][][]byte{}
+	for _, part := range parts {
+		s := GenShares(part, 2)
+		shares[0] = append(shares[0], s[0])
+		shares[1] = append(shares[1], s[1])
+	}
+	var v [2]*SketchVerifier
+	for i := range v {
+		var err error
+		if v[i], err = NewSketchVerifier(i, size, key, shares[i], triples[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r1 := [2][]uint64{v[0].Round1(), v[1].Round1()}
+	var r2 [2][]uint64
+	for i := range v {
+		var err error
+		if r2[i], err = v[i].Round2(r1[1-i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v[0].Verify(r2[0], r2[1]) != v[1].Verify(r2[1], r2[0]) {
+		t.Fatal("servers disagree")
+	}
+	return v[0].Verify(r2[0], r2[1])
+}
+
+func TestSketch(t *testing.T) {
+	size := 100
+	rateS := 4
+	targets := []uint32{3, 17, 99}
+	parts := SplitColumn(targets, uint32(size), rateS)
+	if !verifyParts(t, size, parts, GenTriples(rateS)) {
+		t.Fatal("valid column rejected")
+	}
+	col := CombineShares(append([][]byte{make([]byte, len(parts[0]))}, parts...))
+	if got := ReadVector(col); len(got) != len(targets) {
+		t.Fatal("parts do not combine to the column", got)
+	}
+
+	// a part with two targets
+	parts[3] = CreateVector([]uint32{5, 6}, uint32(size))
+	if verifyParts(t, size, parts, GenTriples(rateS)) {
+		t.Fatal("part with two targets accepted")
+	}
+	parts[3] = CreateVector([]uint32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, uint32(size))
+	if verifyParts(t, size, parts, GenTriples(rateS)) {
+		t.Fatal("part with ten targets accepted")
+	}
+
+	// a wrong triple is detected, even for a valid column
+	parts[3] = CreateVector(nil, uint32(size))
+	triples := GenTriples(rateS)
+	triples[1][2].Gamma ^= 1
+	if verifyParts(t, size, parts, triples) {
+		t.Fatal("wrong triple accepted")
+	}
+
+	if _, err := DecodeTriples(EncodeTriples(triples[0])[1:], rateS); err == nil {
+		t.Fatal("expected error for short triples")
+	}
+	if decoded, err := DecodeTriples(EncodeTriples(triples[0]), rateS); err != nil || decoded[2] != triples[0][2] {
+		t.Fatal("triples not decoded")
+	}
+}
@@ -0,0 +1,11 @@
+package notify
+
+import (
+	"math/rand"
+)
+
+// Like GenShares, but the shares are derived from seed. This is only meant for reproducible
+// tests, the shares do not hide the input from anyone who knows the seed.
+func GenSharesDeterministicForTesting(input []byte, numShares int, seed int64) [][]byte {
+	return genShares(input, numShares, rand.New(rand.NewSource(seed)))
+}
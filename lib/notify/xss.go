@@ -1,23 +1,32 @@
 package notify
 
 import (
-	"math/rand"
+	"io"
+	"sabot/lib/util"
 
 	"github.com/lukechampine/fastxor"
 )
 
-func GenShares(input []byte, numShares int, seed int64) [][]byte {
+// Splits input into numShares XOR shares, the random shares are drawn from an AES-CTR stream
+// keyed from crypto/rand, so every call uses fresh randomness
+func GenShares(input []byte, numShares int) [][]byte {
+	return genShares(input, numShares, util.RandomPRG())
+}
+
+func genShares(input []byte, numShares int, r io.Reader) [][]byte {
 	shares := make([][]byte, numShares)
-	r := rand.New(rand.NewSource(seed))
 
 	// numShares - 1 random shares
 	for i := 0; i < numShares-1; i++ {
 		s := make([]byte, len(input))
-		r.Read(s)
+		if _, err := io.ReadFull(r, s); err != nil {
+			panic("notify: failed to sample shares")
+		}
 		shares[i] = s
 	}
 	// last share is XOR of all previous shares and input
-	finalShare := input
+	finalShare := make([]byte, len(input))
+	copy(finalShare, input)
 	for i := 0; i < numShares-1; i++ {
 		fastxor.Bytes(finalShare, finalShare, shares[i])
 	}
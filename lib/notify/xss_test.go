@@ -12,7 +12,6 @@ func TestGenAndCombineShares(t *testing.T) {
 	r := rand.New(rand.NewSource(42))
 
 	numShares := 2
-	var seed int64 = 42
 	var size uint32 = 10
 	input := util.RandTargets(r, 2, int(size-1), 0)
 
@@ -41,7 +40,10 @@ func TestGenAndCombineShares(t *testing.T) {
 	}
 
 	// Gen shares
-	shares := GenShares(colVec, numShares, seed)
+	shares := GenShares(colVec, numShares)
+	if reflect.DeepEqual(shares[0], colVec) {
+		t.Fatal("share is equal to the input")
+	}
 
 	// Combine shares
 	outVec := CombineShares(shares)
@@ -52,3 +54,26 @@ func TestGenAndCombineShares(t *testing.T) {
 		t.Fatal("input and output are not equal")
 	}
 }
+
+func TestSharesAreFresh(t *testing.T) {
+	input := CreateVector([]uint32{3, 17}, 1024)
+	a := GenShares(input, 2)
+	b := GenShares(input, 2)
+	if reflect.DeepEqual(a[0], b[0]) {
+		t.Fatal("two calls produced the same random share")
+	}
+	if !reflect.DeepEqual(input, CreateVector([]uint32{3, 17}, 1024)) {
+		t.Fatal("input was modified")
+	}
+	for _, shares := range [][][]byte{a, b} {
+		if !reflect.DeepEqual(CombineShares(shares), input) {
+			t.Fatal("shares do not combine to the input")
+		}
+	}
+
+	c := GenSharesDeterministicForTesting(input, 2, 42)
+	d := GenSharesDeterministicForTesting(input, 2, 42)
+	if !reflect.DeepEqual(c, d) {
+		t.Fatal("deterministic shares differ")
+	}
+}
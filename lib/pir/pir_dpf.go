@@ -30,8 +30,7 @@ func InitPIRClient(dbParams *database.StaticDBParams, source *rand.Rand) *DpfCli
 }
 
 func (c *DpfClient) Query(idx int) ([]*dpf.DPFkey, ReconstructFunc) {
-	numBits := uint64(math.Ceil(math.Log2(float64(c.NRows))))
-	qL, qR := dpf.Gen(uint64(idx), numBits)
+	qL, qR := dpf.Gen(uint64(idx), dpf.DomainBits(uint64(c.NRows)))
 
 	return []*dpf.DPFkey{&qL, &qR}, func(resps []interface{}) ([]byte, error) {
 		queryResps := make([]*DPFQueryResp, len(resps))
@@ -46,6 +45,20 @@ func (c *DpfClient) Query(idx int) ([]*dpf.DPFkey, ReconstructFunc) {
 	}
 }
 
+// returns the wire encoding of a query key, see dpf.ParseKey
+func (c *DpfClient) EncodeKey(key *dpf.DPFkey) []byte {
+	return key.Encode(dpf.DomainBits(uint64(c.NRows)))
+}
+
+// decodes a query key received from a client and checks that it matches the size of db
+func ParseKey(db *database.StaticDB, data []byte) (*dpf.DPFkey, error) {
+	key, err := dpf.ParseKey(data, dpf.DomainBits(uint64(db.NumRows)))
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
 func (c *DpfClient) DummyQuery() []*dpf.DPFkey {
 	q, _ := c.Query(0)
 	return q
@@ -65,26 +78,118 @@ func (c *DpfClient) Reconstruct(resp [][]byte) ([]byte, error) {
 	return out, nil
 }
 
+const (
+	// rows per cache block in ProcessBatch are chosen such that a block fits into L2
+	batchBlockBytes = 1 << 18
+	// number of 128-leaf DPF blocks Process buffers before accumulating rows (1 KiB of bits)
+	streamWindowBlocks = 64
+	// the DPF tree is split into subtreesPerWorker*workers subtrees for parallel evaluation
+	subtreesPerWorker = 4
+)
+
 func matVecProduct(db *database.StaticDB, bitVector []byte) []byte {
 	out := make([]byte, db.RowLen)
+	matVecProductRange(db, bitVector, 0, db.NumRows, out, make([]byte, db.RowLen))
+	return out
+}
+
+/*
+XORs all rows in [from, to) whose bit is set into out, bit j of bitVector belongs to row from+j.
+tmp is scratch space of RowLen bytes, allocated once by the caller since this runs for every window or block
+*/
+func matVecProductRange(db *database.StaticDB, bitVector []byte, from, to int, out []byte, tmp []byte) {
 	if db.RowLen == 32 {
-		XorHashesByBitVector(db.FlatDb, bitVector, out)
+		XorHashesByBitVector(db.Slice(from, to), bitVector, tmp)
+		database.XorInto(out, tmp)
 	} else {
-		var j uint
-		for j = 0; j < uint(db.NumRows); j++ {
-			if ((1 << (j % 8)) & bitVector[j/8]) != 0 {
-				database.XorInto(out, db.FlatDb[j*uint(db.RowLen):(j+1)*uint(db.RowLen)])
-			}
-		}
+		XorRowsByBitVector(db.Slice(from, to), bitVector, out)
 	}
-	return out
 }
 
+// The DPF is expanded in blocks of 128 leaves and the matching rows are XORed into the answer
+// as soon as a small window of blocks is filled, so the full bit vector is never materialized.
 func Process(db *database.StaticDB, key *dpf.DPFkey) (*DPFQueryResp, error) {
-	bitVec := dpf.EvalFull(*key, uint64(math.Ceil(math.Log2(float64(db.NumRows)))))
+	out := make([]byte, db.RowLen)
+	tmp := make([]byte, db.RowLen)
+	window := make([]byte, 0, streamWindowBlocks*16)
+	from := 0
+	flush := func() {
+		to := from + 8*len(window)
+		if to > db.NumRows {
+			to = db.NumRows
+		}
+		if from < to {
+			matVecProductRange(db, window, from, to, out, tmp)
+		}
+		from = to
+		window = window[:0]
+	}
+	n := uint64(db.NumRows)
+	dpf.EvalFullStreamN(*key, dpf.DomainBits(n), n, func(blockIdx uint64, leaves []byte) {
+		window = append(window, leaves...)
+		if len(window) == cap(window) {
+			flush()
+		}
+	})
+	flush()
+	return &DPFQueryResp{out}, nil
+}
+
+// Like Process, but the DPF is expanded on up to workers cores if workers > 1
+func ProcessParallel(db *database.StaticDB, key *dpf.DPFkey, workers int) (*DPFQueryResp, error) {
+	if workers <= 1 {
+		return Process(db, key)
+	}
+	bitVec := evalFull(key, uint64(db.NumRows), workers)
 	return &DPFQueryResp{matVecProduct(db, bitVec)}, nil
 }
 
+// expands the first n leaves of the DPF, only the subtrees covering the rows are evaluated
+func evalFull(key *dpf.DPFkey, n uint64, workers int) []byte {
+	logN := dpf.DomainBits(n)
+	if workers <= 1 {
+		return dpf.EvalFullN(*key, logN, n)
+	}
+	splitDepth := dpf.DomainBits(uint64(subtreesPerWorker * workers))
+	return dpf.EvalFullParallelN(*key, logN, n, splitDepth, workers)
+}
+
+// Like ProcessBatchParallel on a single core
+func ProcessBatch(db *database.StaticDB, keys []*dpf.DPFkey) ([]*DPFQueryResp, error) {
+	return ProcessBatchParallel(db, keys, 1)
+}
+
+/*
+Answers many queries with a single pass over the database.
+All keys are expanded first, then the database is walked in cache-sized blocks of rows
+and each block is XORed into every answer whose bit is set before moving on.
+Each row is thus loaded from memory once instead of once per query.
+The DPFs are expanded on up to workers cores.
+*/
+func ProcessBatchParallel(db *database.StaticDB, keys []*dpf.DPFkey, workers int) ([]*DPFQueryResp, error) {
+	bitVecs := make([][]byte, len(keys))
+	resps := make([]*DPFQueryResp, len(keys))
+	for i, key := range keys {
+		bitVecs[i] = evalFull(key, uint64(db.NumRows), workers)
+		resps[i] = &DPFQueryResp{make([]byte, db.RowLen)}
+	}
+
+	tmp := make([]byte, db.RowLen)
+	blockRows := batchBlockBytes / db.RowLen
+	// blocks have to start at a byte boundary of the bit vectors
+	blockRows = (blockRows + 7) &^ 7
+	for from := 0; from < db.NumRows; from += blockRows {
+		to := from + blockRows
+		if to > db.NumRows {
+			to = db.NumRows
+		}
+		for i := range keys {
+			matVecProductRange(db, bitVecs[i][from/8:], from, to, resps[i].Answer, tmp)
+		}
+	}
+	return resps, nil
+}
+
 func Process_old(db *database.StaticDB, key *dpf.DPFkey) (interface{}, error) {
 	bitVec := dpf.EvalFull(*key, uint64(math.Ceil(math.Log2(float64(db.NumRows)))))
 	return &DPFQueryResp{matVecProduct(db, bitVec)}, nil
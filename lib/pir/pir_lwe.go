@@ -0,0 +1,249 @@
+package pir
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sabot/lib/database"
+	"sabot/lib/util"
+	"sort"
+	"sync"
+)
+
+/*
+Single-server PIR based on LWE, following SimplePIR (Henzinger et al., USENIX Security '23).
+The database is laid out as a matrix D over Z_p with one record byte per entry,
+the public matrix A is expanded from a seed and the server publishes the hint D*A once.
+A query is A*s + e + Delta*u_col mod q, the answer is D*query and the client
+removes hint*s to recover the column of the requested record.
+Privacy does not depend on non-collusion of the servers, at the cost of a large hint
+and a linear amount of work per query on the server.
+*/
+
+const (
+	LWESecretDim = 1024    // LWE dimension n
+	LWEPlainMod  = 256     // plaintext modulus p, one database byte per entry
+	lweDelta     = 1 << 24 // q/p with q = 2^32
+	lweSigma     = 6.4     // standard deviation of the error distribution
+	lweTail      = 77      // error distribution is cut off at ~12 sigma
+)
+
+type LWEParams struct {
+	N          uint32      // LWE secret dimension
+	NumRecords int         // number of database records
+	RowLen     int         // record length in bytes
+	NumCols    int         // number of matrix columns (= query length)
+	M          int         // number of matrix rows (= answer length), RowLen entries per record
+	Seed       util.PRGKey // seed to expand the public matrix A (NumCols x N) from
+}
+
+type LWEDB struct {
+	*LWEParams
+	Data []byte   // database matrix D, M x NumCols, row-major
+	Hint []uint32 // D*A, M x N, row-major
+}
+
+type LWEClient struct {
+	*LWEParams
+	Hint []uint32
+	a    []uint32
+	rand io.Reader
+}
+
+type LWEQuery struct {
+	Vec []uint32
+}
+
+type LWEQueryResp struct {
+	Answer []uint32
+}
+
+// Records are placed in a (NumRecords/NumCols) x NumCols grid, NumCols is chosen such that
+// query length and answer length are balanced.
+func NewLWEParams(numRecords int, rowLen int, seed *util.PRGKey) *LWEParams {
+	numCols := int(math.Ceil(math.Sqrt(float64(numRecords * rowLen))))
+	if numCols > numRecords {
+		numCols = numRecords
+	}
+	if numCols < 1 {
+		numCols = 1
+	}
+	recordsPerCol := (numRecords + numCols - 1) / numCols
+	return &LWEParams{
+		N:          LWESecretDim,
+		NumRecords: numRecords,
+		RowLen:     rowLen,
+		NumCols:    numCols,
+		M:          recordsPerCol * rowLen,
+		Seed:       *seed,
+	}
+}
+
+// returns the column of record idx and the matrix row its first byte is stored in
+func (p *LWEParams) position(idx int) (int, int) {
+	return idx % p.NumCols, (idx / p.NumCols) * p.RowLen
+}
+
+// expands the public matrix A (NumCols x N, row-major) from the seed
+func (p *LWEParams) expandA() []uint32 {
+	buf := make([]byte, 4*p.NumCols*int(p.N))
+	util.NewPRG(&p.Seed).Read(buf)
+	a := make([]uint32, p.NumCols*int(p.N))
+	for i := range a {
+		a[i] = binary.LittleEndian.Uint32(buf[4*i:])
+	}
+	return a
+}
+
+// Builds the LWE database matrix from a StaticDB and computes the hint D*A
+func NewLWEDB(db *database.StaticDB, seed *util.PRGKey) *LWEDB {
+	p := NewLWEParams(db.NumRows, db.RowLen, seed)
+	lweDb := &LWEDB{LWEParams: p}
+	lweDb.Data = make([]byte, p.M*p.NumCols)
+	for i := 0; i < db.NumRows; i++ {
+		col, row := p.position(i)
+		for b, v := range db.Row(i) {
+			lweDb.Data[(row+b)*p.NumCols+col] = v
+		}
+	}
+	lweDb.Hint = lweDb.mulA(p.expandA())
+	return lweDb
+}
+
+// computes D*A, rows are distributed over all available cores
+func (db *LWEDB) mulA(a []uint32) []uint32 {
+	n := int(db.N)
+	hint := make([]uint32, db.M*n)
+	numWorkers := runtime.NumCPU()
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for r := w; r < db.M; r += numWorkers {
+				out := hint[r*n : (r+1)*n]
+				for c, d := range db.Data[r*db.NumCols : (r+1)*db.NumCols] {
+					if d == 0 {
+						continue
+					}
+					aRow := a[c*n : (c+1)*n]
+					for j := range out {
+						out[j] += uint32(d) * aRow[j]
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	return hint
+}
+
+// returns the rows [from, to) of the hint
+func (db *LWEDB) HintRows(from, to int) ([]uint32, error) {
+	if from < 0 || to > db.M || from > to {
+		return nil, fmt.Errorf("invalid hint range [%d, %d) for %d rows", from, to, db.M)
+	}
+	return db.Hint[from*int(db.N) : to*int(db.N)], nil
+}
+
+func ProcessLWE(db *LWEDB, q *LWEQuery) (*LWEQueryResp, error) {
+	if len(q.Vec) != db.NumCols {
+		return nil, fmt.Errorf("invalid query length: %d, expected %d", len(q.Vec), db.NumCols)
+	}
+	out := make([]uint32, db.M)
+	for r := range out {
+		var acc uint32
+		for c, d := range db.Data[r*db.NumCols : (r+1)*db.NumCols] {
+			acc += uint32(d) * q.Vec[c]
+		}
+		out[r] = acc
+	}
+	return &LWEQueryResp{out}, nil
+}
+
+func InitLWEClient(params *LWEParams, hint []uint32) *LWEClient {
+	return &LWEClient{params, hint, params.expandA(), util.RandomPRG()}
+}
+
+func (c *LWEClient) Query(idx int) (*LWEQuery, ReconstructFunc) {
+	n := int(c.N)
+	s := make([]uint32, n)
+	buf := make([]byte, 4*n)
+	if _, err := io.ReadFull(c.rand, buf); err != nil {
+		panic("lwe: failed to sample secret")
+	}
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint32(buf[4*i:])
+	}
+
+	col, row := c.position(idx)
+	vec := make([]uint32, c.NumCols)
+	for i := range vec {
+		acc := uint32(sampleError(c.rand))
+		for j, a := range c.a[i*n : (i+1)*n] {
+			acc += a * s[j]
+		}
+		vec[i] = acc
+	}
+	vec[col] += lweDelta
+
+	return &LWEQuery{vec}, func(resps []interface{}) ([]byte, error) {
+		if len(resps) != 1 {
+			return nil, fmt.Errorf("Invalid number of responses: %d, expected 1", len(resps))
+		}
+		resp, ok := resps[0].(*LWEQueryResp)
+		if !ok {
+			return nil, fmt.Errorf("Invalid response type: %T, expected *LWEQueryResp", resps[0])
+		}
+		return c.reconstruct(resp, row, s)
+	}
+}
+
+func (c *LWEClient) DummyQuery() *LWEQuery {
+	q, _ := c.Query(0)
+	return q
+}
+
+func (c *LWEClient) reconstruct(resp *LWEQueryResp, row int, s []uint32) ([]byte, error) {
+	if len(resp.Answer) != c.M {
+		return nil, errors.New("answer has wrong length")
+	}
+	n := int(c.N)
+	out := make([]byte, c.RowLen)
+	for b := range out {
+		noisy := resp.Answer[row+b]
+		for j, h := range c.Hint[(row+b)*n : (row+b+1)*n] {
+			noisy -= h * s[j]
+		}
+		// round to the nearest multiple of Delta
+		out[b] = byte((noisy + lweDelta/2) / lweDelta)
+	}
+	return out, nil
+}
+
+// cumulative distribution of the rounded Gaussian on [-lweTail, lweTail]
+var lweErrCDF = func() []float64 {
+	t := lweTail
+	cdf := make([]float64, 2*t+1)
+	var sum float64
+	for x := -t; x <= t; x++ {
+		sum += math.Exp(-float64(x*x) / (2 * lweSigma * lweSigma))
+		cdf[x+t] = sum
+	}
+	for i := range cdf {
+		cdf[i] /= sum
+	}
+	return cdf
+}()
+
+func sampleError(r io.Reader) int32 {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		panic("lwe: failed to sample error")
+	}
+	u := float64(binary.LittleEndian.Uint64(buf[:])>>11) / (1 << 53)
+	return int32(sort.SearchFloat64s(lweErrCDF, u)) - lweTail
+}
@@ -2,7 +2,10 @@ package pir
 
 import (
 	"reflect"
+	"sabot/lib/util"
 	"testing"
+
+	"github.com/dkales/dpf-go/dpf"
 )
 
 func TestDPF(t *testing.T) {
@@ -37,3 +40,136 @@ func TestDPF(t *testing.T) {
 		t.Fatal("retrieved element does not match")
 	}
 }
+
+func TestLWE(t *testing.T) {
+	db := MakeDB(512, 32)
+	if db == nil {
+		t.Fatal("error making test db")
+	}
+	var seed util.PRGKey
+	RandSource().Read(seed[:])
+	lweDb := NewLWEDB(db, &seed)
+	client := InitLWEClient(lweDb.LWEParams, lweDb.Hint)
+
+	for _, i := range []int{0, 128, 301, 511} {
+		query, reconstructFunc := client.Query(i)
+		resp, err := ProcessLWE(lweDb, query)
+		if err != nil {
+			t.Fatalf("server failed to answer: %v", err)
+		}
+		res, err := reconstructFunc([]interface{}{resp})
+		if err != nil {
+			t.Fatalf("failed to reconstruct answer: %v", err)
+		}
+		if !reflect.DeepEqual(res, db.Row(i)) {
+			t.Fatalf("retrieved element %d does not match", i)
+		}
+	}
+}
+
+func TestDPFBatch(t *testing.T) {
+	for _, rowLen := range []int{32, 45} {
+		db := MakeDB(20000, rowLen)
+		if db == nil {
+			t.Fatal("error making test db")
+		}
+		client := InitPIRClient(db.Params(), RandSource())
+
+		indices := []int{0, 7, 8191, 8192, 19999}
+		keys := make([][]*dpf.DPFkey, 2)
+		for _, i := range indices {
+			queryReq, _ := client.Query(i)
+			keys[Left] = append(keys[Left], queryReq[Left])
+			keys[Right] = append(keys[Right], queryReq[Right])
+		}
+		resps := make([][]*DPFQueryResp, 2)
+		for s := range keys {
+			var err error
+			resps[s], err = ProcessBatch(db, keys[s])
+			if err != nil {
+				t.Fatalf("server %d failed to answer: %v", s, err)
+			}
+			// batched answers equal single answers
+			for j, key := range keys[s] {
+				single, _ := Process(db, key)
+				if !reflect.DeepEqual(single.Answer, resps[s][j].Answer) {
+					t.Fatalf("batched answer %d differs from single answer", j)
+				}
+			}
+		}
+		for j, i := range indices {
+			res, _ := client.Reconstruct([][]byte{resps[Left][j].Answer, resps[Right][j].Answer})
+			if !reflect.DeepEqual(res, db.Row(i)) {
+				t.Fatalf("retrieved element %d does not match", i)
+			}
+		}
+	}
+}
+
+func benchmarkProcess(b *testing.B, batched bool) {
+	db := MakeDB(1<<18, 32)
+	client := InitPIRClient(db.Params(), RandSource())
+	keys := make([]*dpf.DPFkey, 30)
+	for i := range keys {
+		queryReq, _ := client.Query(i)
+		keys[i] = queryReq[Left]
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if batched {
+			ProcessBatch(db, keys)
+		} else {
+			for _, key := range keys {
+				Process(db, key)
+			}
+		}
+	}
+}
+
+func BenchmarkProcess(b *testing.B)      { benchmarkProcess(b, false) }
+func BenchmarkProcessBatch(b *testing.B) { benchmarkProcess(b, true) }
+
+func TestDPFParallel(t *testing.T) {
+	db := MakeDB(5000, 32)
+	client := InitPIRClient(db.Params(), RandSource())
+	queryReq, _ := client.Query(4321)
+	for _, workers := range []int{1, 2, 5} {
+		resps := make([][]byte, 2)
+		for s := range resps {
+			resp, err := ProcessParallel(db, queryReq[s], workers)
+			if err != nil {
+				t.Fatalf("server %d failed to answer: %v", s, err)
+			}
+			batch, _ := ProcessBatchParallel(db, queryReq[s:s+1], workers)
+			if !reflect.DeepEqual(resp.Answer, batch[0].Answer) {
+				t.Fatal("parallel batch answer differs")
+			}
+			resps[s] = resp.Answer
+		}
+		res, _ := client.Reconstruct(resps)
+		if !reflect.DeepEqual(res, db.Row(4321)) {
+			t.Fatalf("retrieved element does not match with %d workers", workers)
+		}
+	}
+}
+
+func TestDPFNonPowerOfTwo(t *testing.T) {
+	for _, numRows := range []int{1, 129, 1000, 4097} {
+		db := MakeDB(numRows, 20)
+		client := InitPIRClient(db.Params(), RandSource())
+		i := numRows - 1
+		queryReq, _ := client.Query(i)
+		resps := make([][]byte, 2)
+		for s := range resps {
+			resp, err := Process(db, queryReq[s])
+			if err != nil {
+				t.Fatalf("server %d failed to answer: %v", s, err)
+			}
+			resps[s] = resp.Answer
+		}
+		res, _ := client.Reconstruct(resps)
+		if !reflect.DeepEqual(res, db.Row(i)) {
+			t.Fatalf("retrieved element does not match for %d rows", numRows)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package pir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sabot/lib/database"
+	"testing"
+
+	"github.com/dkales/dpf-go/dpf"
+)
+
+var updateVectors = flag.Bool("update", false, "regenerate the test vectors in testdata")
+
+var vectorsFile = filepath.Join("testdata", "vectors.json")
+
+// byte strings are hex encoded in the test vectors
+type hexBytes []byte
+
+func (h hexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(h)), nil
+}
+
+func (h *hexBytes) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	*h = b
+	return err
+}
+
+// Seed holds the 32 random bytes read by dpf.Gen, the seed of the first key followed by the seed of the second.
+// Full-domain outputs are bit vectors, leaf i is bit i%8 of byte i/8.
+type dpfVector struct {
+	Seed      hexBytes `json:"seed"`
+	Alpha     uint64   `json:"alpha"`
+	LogN      uint64   `json:"logN"`
+	KeyA      hexBytes `json:"keyA"`
+	KeyB      hexBytes `json:"keyB"`
+	EvalFullA hexBytes `json:"evalFullA"`
+	EvalFullB hexBytes `json:"evalFullB"`
+}
+
+// keys are given in the wire encoding sent to the servers (see dpf.ParseKey)
+type pirVector struct {
+	Seed    hexBytes `json:"seed"`
+	Index   int      `json:"index"`
+	KeyA    hexBytes `json:"keyA"`
+	KeyB    hexBytes `json:"keyB"`
+	AnswerA hexBytes `json:"answerA"`
+	AnswerB hexBytes `json:"answerB"`
+}
+
+type testVectors struct {
+	Comment string      `json:"comment"`
+	DPF     []dpfVector `json:"dpf"`
+	PIR     struct {
+		Rows    []hexBytes  `json:"rows"`
+		Queries []pirVector `json:"queries"`
+	} `json:"pir"`
+}
+
+func deriveDPFVector(seed []byte, alpha, logN uint64) dpfVector {
+	a, b := dpf.GenWithReader(alpha, logN, bytes.NewReader(seed))
+	return dpfVector{seed, alpha, logN, hexBytes(a), hexBytes(b), dpf.EvalFull(a, logN), dpf.EvalFull(b, logN)}
+}
+
+func derivePIRVector(db *database.StaticDB, seed []byte, idx int) pirVector {
+	client := InitPIRClient(db.Params(), nil)
+	logN := dpf.DomainBits(uint64(db.NumRows))
+	a, b := dpf.GenWithReader(uint64(idx), logN, bytes.NewReader(seed))
+	respA, _ := Process(db, &a)
+	respB, _ := Process(db, &b)
+	return pirVector{seed, idx, client.EncodeKey(&a), client.EncodeKey(&b), respA.Answer, respB.Answer}
+}
+
+func vectorsDB(rows []hexBytes) *database.StaticDB {
+	data := make([][]byte, len(rows))
+	for i := range rows {
+		data[i] = rows[i]
+	}
+	db, err := database.StaticDBFromRows(data)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+func generateVectors() *testVectors {
+	src := rand.New(rand.NewSource(2024))
+	seed := func() []byte {
+		s := make([]byte, 32)
+		src.Read(s)
+		return s
+	}
+	v := &testVectors{Comment: "DPF keys and outputs of github.com/dkales/dpf-go/dpf and PIR answers of sabot/lib/pir, regenerate with go test ./lib/pir -run TestVectors -update"}
+	for _, c := range [][2]uint64{{0, 0}, {5, 3}, {127, 7}, {128, 8}, {1000, 10}, {3000, 12}} {
+		v.DPF = append(v.DPF, deriveDPFVector(seed(), c[0], c[1]))
+	}
+	// a database with a number of rows that is not a power of two
+	for i := 0; i < 20; i++ {
+		row := make([]byte, 32)
+		src.Read(row)
+		v.PIR.Rows = append(v.PIR.Rows, row)
+	}
+	db := vectorsDB(v.PIR.Rows)
+	for _, idx := range []int{0, 7, 19} {
+		v.PIR.Queries = append(v.PIR.Queries, derivePIRVector(db, seed(), idx))
+	}
+	return v
+}
+
+func TestVectors(t *testing.T) {
+	if *updateVectors {
+		data, err := json.MarshalIndent(generateVectors(), "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(vectorsFile, append(data, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(vectorsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v testVectors
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vec := range v.DPF {
+		if got := deriveDPFVector(vec.Seed, vec.Alpha, vec.LogN); !reflect.DeepEqual(got, vec) {
+			t.Errorf("DPF vector for alpha=%d, logN=%d does not match", vec.Alpha, vec.LogN)
+		}
+	}
+
+	db := vectorsDB(v.PIR.Rows)
+	client := InitPIRClient(db.Params(), nil)
+	for _, vec := range v.PIR.Queries {
+		if got := derivePIRVector(db, vec.Seed, vec.Index); !reflect.DeepEqual(got, vec) {
+			t.Errorf("PIR vector for index %d does not match", vec.Index)
+		}
+		row, _ := client.Reconstruct([][]byte{vec.AnswerA, vec.AnswerB})
+		if !bytes.Equal(row, db.Row(vec.Index)) {
+			t.Errorf("PIR vector for index %d does not reconstruct the row", vec.Index)
+		}
+	}
+}
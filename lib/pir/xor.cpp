@@ -1,7 +1,10 @@
+//go:build cgo && !purego
+
 #include <cstdint>
 #include <cstdio>
 #include <cstring>
 #include "intrinsics.h"
+#include "xor.h"
 
 extern "C"
 {
@@ -292,4 +295,149 @@ extern "C"
 
 #endif // __AVX2__
 
+    // XORs row into out, both of length len, using 128-bit registers
+    static inline void xor_row_sse(uint8_t* out, const uint8_t* row, unsigned int len)
+    {
+        unsigned int i = 0;
+        for (; i + 16 <= len; i += 16)
+        {
+            __m128i acc = _mm_loadu_si128((__m128i *)(out + i));
+            acc = _mm_xor_si128(acc, _mm_loadu_si128((__m128i *)(row + i)));
+            _mm_storeu_si128((__m128i *)(out + i), acc);
+        }
+        for (; i < len; i++)
+        {
+            out[i] ^= row[i];
+        }
+    }
+
+#ifdef __amd64__
+
+    __attribute__((target("avx2")))
+    static inline void xor_row_avx2(uint8_t* out, const uint8_t* row, unsigned int len)
+    {
+        unsigned int i = 0;
+        for (; i + 32 <= len; i += 32)
+        {
+            __m256i acc = _mm256_loadu_si256((__m256i *)(out + i));
+            acc = _mm256_xor_si256(acc, _mm256_loadu_si256((__m256i *)(row + i)));
+            _mm256_storeu_si256((__m256i *)(out + i), acc);
+        }
+        for (; i + 16 <= len; i += 16)
+        {
+            __m128i acc = _mm_loadu_si128((__m128i *)(out + i));
+            acc = _mm_xor_si128(acc, _mm_loadu_si128((__m128i *)(row + i)));
+            _mm_storeu_si128((__m128i *)(out + i), acc);
+        }
+        for (; i < len; i++)
+        {
+            out[i] ^= row[i];
+        }
+    }
+
+    __attribute__((target("avx512f")))
+    static inline void xor_row_avx512(uint8_t* out, const uint8_t* row, unsigned int len)
+    {
+        unsigned int i = 0;
+        for (; i + 64 <= len; i += 64)
+        {
+            __m512i acc = _mm512_loadu_si512((void *)(out + i));
+            acc = _mm512_xor_si512(acc, _mm512_loadu_si512((void *)(row + i)));
+            _mm512_storeu_si512((void *)(out + i), acc);
+        }
+        for (; i + 32 <= len; i += 32)
+        {
+            __m256i acc = _mm256_loadu_si256((__m256i *)(out + i));
+            acc = _mm256_xor_si256(acc, _mm256_loadu_si256((__m256i *)(row + i)));
+            _mm256_storeu_si256((__m256i *)(out + i), acc);
+        }
+        for (; i + 16 <= len; i += 16)
+        {
+            __m128i acc = _mm_loadu_si128((__m128i *)(out + i));
+            acc = _mm_xor_si128(acc, _mm_loadu_si128((__m128i *)(row + i)));
+            _mm_storeu_si128((__m128i *)(out + i), acc);
+        }
+        for (; i < len; i++)
+        {
+            out[i] ^= row[i];
+        }
+    }
+
+    __attribute__((target("avx2")))
+    static void xor_rows_by_bit_vector_avx2(const uint8_t* db, unsigned int num_rows, 
+        unsigned int row_len, const uint8_t* indexing, uint8_t* out)
+    {
+        for (unsigned int i = 0; i < num_rows; i += 8)
+        {
+            uint8_t bits = indexing[i / 8];
+            while (bits)
+            {
+                unsigned int r = i + __builtin_ctz(bits);
+                bits &= bits - 1;
+                if (r >= num_rows)
+                    break;
+                xor_row_avx2(out, db + (size_t)r * row_len, row_len);
+            }
+        }
+    }
+
+    __attribute__((target("avx512f")))
+    static void xor_rows_by_bit_vector_avx512(const uint8_t* db, unsigned int num_rows, 
+        unsigned int row_len, const uint8_t* indexing, uint8_t* out)
+    {
+        for (unsigned int i = 0; i < num_rows; i += 8)
+        {
+            uint8_t bits = indexing[i / 8];
+            while (bits)
+            {
+                unsigned int r = i + __builtin_ctz(bits);
+                bits &= bits - 1;
+                if (r >= num_rows)
+                    break;
+                xor_row_avx512(out, db + (size_t)r * row_len, row_len);
+            }
+        }
+    }
+
+#endif // __amd64__
+
+    static void xor_rows_by_bit_vector_sse(const uint8_t* db, unsigned int num_rows, 
+        unsigned int row_len, const uint8_t* indexing, uint8_t* out)
+    {
+        for (unsigned int i = 0; i < num_rows; i += 8)
+        {
+            uint8_t bits = indexing[i / 8];
+            while (bits)
+            {
+                unsigned int r = i + __builtin_ctz(bits);
+                bits &= bits - 1;
+                if (r >= num_rows)
+                    break;
+                xor_row_sse(out, db + (size_t)r * row_len, row_len);
+            }
+        }
+    }
+
+    // XORs every row of length row_len whose bit in indexing is set into out (of length row_len).
+    // kernel is one of XOR_KERNEL_*, the caller is responsible for checking CPU support.
+    void xor_rows_by_bit_vector(const uint8_t* db, unsigned int db_len, 
+        unsigned int row_len, const uint8_t* indexing, 
+        uint8_t* out, int kernel)
+    {
+        unsigned int num_rows = db_len / row_len;
+#ifdef __amd64__
+        if (kernel == XOR_KERNEL_AVX512)
+        {
+            xor_rows_by_bit_vector_avx512(db, num_rows, row_len, indexing, out);
+            return;
+        }
+        if (kernel == XOR_KERNEL_AVX2)
+        {
+            xor_rows_by_bit_vector_avx2(db, num_rows, row_len, indexing, out);
+            return;
+        }
+#endif // __amd64__
+        xor_rows_by_bit_vector_sse(db, num_rows, row_len, indexing, out);
+    }
+
 } // extern "C"
@@ -15,6 +15,15 @@ void xor_hashes_by_bit_vector(const uint8_t* db, unsigned int db_len,
     const uint8_t* indexing, 
     uint8_t* out);
 
+// kernel levels for xor_rows_by_bit_vector, selected at runtime
+#define XOR_KERNEL_SSE 0
+#define XOR_KERNEL_AVX2 1
+#define XOR_KERNEL_AVX512 2
+
+void xor_rows_by_bit_vector(const uint8_t* db, unsigned int db_len, 
+    unsigned int row_len, const uint8_t* indexing, 
+    uint8_t* out, int kernel);
+
 #ifdef __cplusplus
 }
 #endif
@@ -1,3 +1,5 @@
+//go:build cgo && !purego
+
 package pir
 
 /*
@@ -9,8 +11,27 @@ package pir
 import "C"
 import (
 	"unsafe"
+
+	"github.com/klauspost/cpuid/v2"
 )
 
+const (
+	xorKernelSSE    = int(C.XOR_KERNEL_SSE)
+	xorKernelAVX2   = int(C.XOR_KERNEL_AVX2)
+	xorKernelAVX512 = int(C.XOR_KERNEL_AVX512)
+)
+
+// widest XOR kernel supported by the CPU we are running on
+var xorKernel = func() int {
+	if cpuid.CPU.Supports(cpuid.AVX512F) {
+		return xorKernelAVX512
+	}
+	if cpuid.CPU.Supports(cpuid.AVX2) {
+		return xorKernelAVX2
+	}
+	return xorKernelSSE
+}()
+
 func XorBlocks(db []byte, offsets []int, out []byte) {
 	C.xor_rows((*C.uchar)(&db[0]), C.uint(len(db)), (*C.ulonglong)(unsafe.Pointer(&offsets[0])), C.uint(len(offsets)), C.uint(len(out)), (*C.uchar)(&out[0]))
 }
@@ -19,3 +40,16 @@ func XorHashesByBitVector(db []byte, indexing []byte, out []byte) {
 	C.xor_hashes_by_bit_vector((*C.uchar)(&db[0]), C.uint(len(db)),
 		(*C.uchar)(&indexing[0]), (*C.uchar)(&out[0]))
 }
+
+// XORs every row of db (rows of len(out) bytes) whose bit in indexing is set into out
+func XorRowsByBitVector(db []byte, indexing []byte, out []byte) {
+	xorRowsByBitVector(db, indexing, out, xorKernel)
+}
+
+func xorRowsByBitVector(db []byte, indexing []byte, out []byte, kernel int) {
+	if len(db) < len(out) {
+		return
+	}
+	C.xor_rows_by_bit_vector((*C.uchar)(&db[0]), C.uint(len(db)), C.uint(len(out)),
+		(*C.uchar)(&indexing[0]), (*C.uchar)(&out[0]), C.int(kernel))
+}
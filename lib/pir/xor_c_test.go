@@ -0,0 +1,77 @@
+//go:build cgo && !purego
+
+package pir
+
+import (
+	"fmt"
+	"reflect"
+	"sabot/lib/database"
+	"testing"
+)
+
+// kernels the CPU can run, the widest one is used by XorRowsByBitVector
+func supportedXorKernels() []int {
+	kernels := []int{xorKernelSSE}
+	if xorKernel >= xorKernelAVX2 {
+		kernels = append(kernels, xorKernelAVX2)
+	}
+	if xorKernel >= xorKernelAVX512 {
+		kernels = append(kernels, xorKernelAVX512)
+	}
+	return kernels
+}
+
+func TestXorRowsByBitVector(t *testing.T) {
+	src := RandSource()
+	for _, rowLen := range []int{1, 7, 16, 33, 36, 64, 100, 617} {
+		numRows := 1000
+		db := make([]byte, numRows*rowLen)
+		src.Read(db)
+		bits := make([]byte, (numRows+7)/8)
+		src.Read(bits)
+
+		expected := make([]byte, rowLen)
+		for j := 0; j < numRows; j++ {
+			if (bits[j/8]>>(j%8))&1 == 1 {
+				database.XorInto(expected, db[j*rowLen:(j+1)*rowLen])
+			}
+		}
+		for _, kernel := range supportedXorKernels() {
+			out := make([]byte, rowLen)
+			xorRowsByBitVector(db, bits, out, kernel)
+			if !reflect.DeepEqual(out, expected) {
+				t.Fatalf("kernel %d: wrong result for row length %d", kernel, rowLen)
+			}
+		}
+	}
+}
+
+func BenchmarkXorRowsByBitVector(b *testing.B) {
+	src := RandSource()
+	numRows := 1 << 16
+	for _, rowLen := range []int{32, 36, 68, 100, 256, 1024} {
+		db := make([]byte, numRows*rowLen)
+		src.Read(db)
+		bits := make([]byte, numRows/8)
+		src.Read(bits)
+		out := make([]byte, rowLen)
+		b.Run(fmt.Sprintf("go/%d", rowLen), func(b *testing.B) {
+			b.SetBytes(int64(len(db)))
+			for n := 0; n < b.N; n++ {
+				for j := 0; j < numRows; j++ {
+					if (bits[j/8]>>(j%8))&1 == 1 {
+						database.XorInto(out, db[j*rowLen:(j+1)*rowLen])
+					}
+				}
+			}
+		})
+		for _, kernel := range supportedXorKernels() {
+			b.Run(fmt.Sprintf("kernel%d/%d", kernel, rowLen), func(b *testing.B) {
+				b.SetBytes(int64(len(db)))
+				for n := 0; n < b.N; n++ {
+					xorRowsByBitVector(db, bits, out, kernel)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package pir
+
+import (
+	"sabot/lib/database"
+)
+
+// Portable implementations of the C kernels in xor.cpp, used when building without cgo
+// or with the purego build tag. They produce the same results as the C kernels.
+
+func xorBlocksGeneric(db []byte, offsets []int, out []byte) {
+	for i := range out {
+		out[i] = 0
+	}
+	for _, off := range offsets {
+		if off < 0 || off > len(db)-len(out) {
+			continue
+		}
+		database.XorInto(out, db[off:off+len(out)])
+	}
+}
+
+func xorHashesByBitVectorGeneric(db []byte, indexing []byte, out []byte) {
+	for i := range out[:32] {
+		out[i] = 0
+	}
+	xorRowsByBitVectorGeneric(db, indexing, out[:32])
+}
+
+func xorRowsByBitVectorGeneric(db []byte, indexing []byte, out []byte) {
+	rowLen := len(out)
+	for j := 0; j < len(db)/rowLen; j++ {
+		if (indexing[j/8]>>(j%8))&1 == 1 {
+			database.XorInto(out, db[j*rowLen:(j+1)*rowLen])
+		}
+	}
+}
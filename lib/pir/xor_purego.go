@@ -0,0 +1,16 @@
+//go:build !cgo || purego
+
+package pir
+
+func XorBlocks(db []byte, offsets []int, out []byte) {
+	xorBlocksGeneric(db, offsets, out)
+}
+
+func XorHashesByBitVector(db []byte, indexing []byte, out []byte) {
+	xorHashesByBitVectorGeneric(db, indexing, out)
+}
+
+// XORs every row of db (rows of len(out) bytes) whose bit in indexing is set into out
+func XorRowsByBitVector(db []byte, indexing []byte, out []byte) {
+	xorRowsByBitVectorGeneric(db, indexing, out)
+}
@@ -0,0 +1,44 @@
+package pir
+
+import (
+	"reflect"
+	"testing"
+)
+
+// the exported kernels (C or pure Go, depending on the build) have to match the portable ones
+func TestXorGenericEquivalence(t *testing.T) {
+	src := RandSource()
+	numRows := 1001
+	for _, rowLen := range []int{1, 13, 32, 48, 100} {
+		db := make([]byte, numRows*rowLen)
+		src.Read(db)
+		bits := make([]byte, (numRows+7)/8)
+		src.Read(bits)
+
+		out := make([]byte, rowLen)
+		outGeneric := make([]byte, rowLen)
+		XorRowsByBitVector(db, bits, out)
+		xorRowsByBitVectorGeneric(db, bits, outGeneric)
+		if !reflect.DeepEqual(out, outGeneric) {
+			t.Fatalf("XorRowsByBitVector differs for row length %d", rowLen)
+		}
+
+		offsets := make([]int, 50)
+		for i := range offsets {
+			offsets[i] = src.Intn(numRows) * rowLen
+		}
+		XorBlocks(db, offsets, out)
+		xorBlocksGeneric(db, offsets, outGeneric)
+		if !reflect.DeepEqual(out, outGeneric) {
+			t.Fatalf("XorBlocks differs for row length %d", rowLen)
+		}
+
+		if rowLen == 32 {
+			XorHashesByBitVector(db, bits, out)
+			xorHashesByBitVectorGeneric(db, bits, outGeneric)
+			if !reflect.DeepEqual(out, outGeneric) {
+				t.Fatal("XorHashesByBitVector differs")
+			}
+		}
+	}
+}
@@ -6,11 +6,17 @@ const (
 	CERT_C_PATH_PRE = "cert/client"
 	CERT_S_PATH_PRE = "cert/server"
 	CERT_CA_PATH    = "cert/ca-cert.pem"
-	DPF_SEED        = 41
 	MAX_MSG_SIZE    = 1024 * 1024 * 64
 	TIMEOUT         = 100 * time.Minute
 	INPUT_SEED      = 42
 	ARITY           = 3  //BFF setup param, = num hash funcs
 	KEY_LENGTH      = 32 //size in byte of client identifier
 	VAL_LENGTH      = 32 // size in byte of contact info
+	// a notification crossing a round flip is repeated for the new epoch
+	MAX_NOTIFY_ATTEMPTS = 5
+	NOTIFY_RETRY_DELAY  = 100 * time.Millisecond
+	// a server waits this long for the other server's part of a column verification
+	SKETCH_TIMEOUT = 10 * time.Second
+	// round control requests of the coordinator are only accepted this long after they are sent
+	ROUND_TIMEOUT = time.Minute
 )
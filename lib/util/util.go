@@ -1,9 +1,12 @@
 package util
 
 import (
+	"bytes"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 	"log"
 	"math/rand"
 	"os"
@@ -68,6 +71,22 @@ func LoadTLSCred(filePre string, caPath string, isClient bool) (credentials.Tran
 	return credentials.NewTLS(config), nil
 }
 
+// reads a hex key of at least 16 bytes from a file, keys are not passed as flags so they do not show up in the process list
+func ReadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(data)
+	trimmed := bytes.TrimSpace(data)
+	key := make([]byte, hex.DecodedLen(len(trimmed)))
+	if _, err := hex.Decode(key, trimmed); err != nil || len(key) < 16 {
+		clear(key)
+		return nil, fmt.Errorf("%s has to hold a hex key of at least 16 bytes", path)
+	}
+	return key, nil
+}
+
 func ByteSliceToUint64(in []byte) uint64 {
 	if len(in)/8 != 1 {
 		log.Fatal("byte array too large")
@@ -89,3 +108,24 @@ func RandTargets(r *rand.Rand, num int, max int, min int) []uint32 {
 	}
 	return targets
 }
+
+// encodes a slice of uint32 (e.g. LWE vectors) as little-endian bytes for the wire
+func Uint32SliceToBytes(in []uint32) []byte {
+	out := make([]byte, 4*len(in))
+	for i, v := range in {
+		binary.LittleEndian.PutUint32(out[4*i:], v)
+	}
+	return out
+}
+
+// decodes little-endian uint32s, bytes from the network are not trusted to have the right length
+func BytesToUint32Slice(in []byte) ([]uint32, error) {
+	if len(in)%4 != 0 {
+		return nil, fmt.Errorf("byte array has wrong size, expected multiple of 4, got len=%d", len(in))
+	}
+	out := make([]uint32, len(in)/4)
+	for i := range out {
+		out[i] = binary.LittleEndian.Uint32(in[4*i:])
+	}
+	return out, nil
+}
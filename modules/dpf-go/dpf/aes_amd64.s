@@ -2,10 +2,11 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build amd64,!gccgo
+//go:build amd64 && !gccgo && !purego
+// +build amd64,!gccgo,!purego
 
 // func xor16(dst, a, b *byte)
-TEXT ·xor16(SB),4,$0
+TEXT ·xor16(SB),4,$0-24
 	MOVQ dst+0(FP), AX
 	MOVQ a+8(FP), BX
 	MOVQ b+16(FP), CX
@@ -16,7 +17,7 @@ TEXT ·xor16(SB),4,$0
 	RET
 
 // func encryptAes128(xk *uint32, dst, src *byte)
-TEXT ·encryptAes128(SB),4,$0
+TEXT ·encryptAes128(SB),4,$0-24
 	MOVQ xk+0(FP), AX
 	MOVQ dst+8(FP), DX
 	MOVQ src+16(FP), BX
@@ -48,7 +49,7 @@ TEXT ·encryptAes128(SB),4,$0
 	RET
 
 // func aes128MMO(xk *uint32, dst, src *byte)
-TEXT ·aes128MMO(SB),4,$0
+TEXT ·aes128MMO(SB),4,$0-24
 	MOVQ xk+0(FP), AX
 	MOVQ dst+8(FP), DX
 	MOVQ src+16(FP), BX
@@ -84,7 +85,7 @@ TEXT ·aes128MMO(SB),4,$0
 
 // func expandKeyAsm(key *byte, enc *uint32) {
 // Note that round keys are stored in uint128 format, not uint32
-TEXT ·expandKeyAsm(SB),4,$0
+TEXT ·expandKeyAsm(SB),4,$0-16
 	MOVQ key+0(FP), AX
 	MOVQ enc+8(FP), BX
 	MOVUPS (AX), X0
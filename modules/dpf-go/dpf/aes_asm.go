@@ -0,0 +1,17 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (amd64 || arm64) && !gccgo && !purego
+// +build amd64 arm64
+// +build !gccgo
+// +build !purego
+
+package dpf
+
+// defined in aes_amd64.s and aes_arm64.s
+// extern xor16
+func xor16(dst, a, b *byte)
+func encryptAes128(xk *uint32, dst, src *byte)
+func aes128MMO(xk *uint32, dst, src *byte)
+func expandKeyAsm(key *byte, enc *uint32)
@@ -0,0 +1,22 @@
+//go:build (!amd64 && !arm64) || gccgo || purego
+// +build !amd64,!arm64 gccgo purego
+
+package dpf
+
+// without assembly support, the portable implementations from aes_generic.go are used
+
+func xor16(dst, a, b *byte) {
+	xor16Generic(dst, a, b)
+}
+
+func encryptAes128(xk *uint32, dst, src *byte) {
+	encryptAes128Generic(xk, dst, src)
+}
+
+func aes128MMO(xk *uint32, dst, src *byte) {
+	aes128MMOGeneric(xk, dst, src)
+}
+
+func expandKeyAsm(key *byte, enc *uint32) {
+	expandKeyGeneric(key, enc)
+}
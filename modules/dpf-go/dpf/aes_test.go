@@ -0,0 +1,57 @@
+package dpf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"testing"
+)
+
+// The portable implementations have to match the assembly (on builds without assembly
+// support both sides are the portable implementation) and the standard library.
+func TestGenericAES(test *testing.T) {
+	for i := 0; i < 100; i++ {
+		key := make([]byte, 16)
+		src := make([]byte, 16)
+		rand.Read(key)
+		rand.Read(src)
+
+		xk := make([]uint32, 11*4)
+		xkGeneric := make([]uint32, 11*4)
+		expandKeyAsm(&key[0], &xk[0])
+		expandKeyGeneric(&key[0], &xkGeneric[0])
+		for j := range xk {
+			if xk[j] != xkGeneric[j] {
+				test.Fatal("key expansion differs")
+			}
+		}
+
+		std, _ := aes.NewCipher(key)
+		expected := make([]byte, 16)
+		std.Encrypt(expected, src)
+		dst := make([]byte, 16)
+		dstGeneric := make([]byte, 16)
+		encryptAes128(&xk[0], &dst[0], &src[0])
+		encryptAes128Generic(&xk[0], &dstGeneric[0], &src[0])
+		if !bytes.Equal(dst, expected) || !bytes.Equal(dstGeneric, expected) {
+			test.Fatal("encryption differs from crypto/aes")
+		}
+
+		aes128MMO(&xk[0], &dst[0], &src[0])
+		aes128MMOGeneric(&xk[0], &dstGeneric[0], &src[0])
+		if !bytes.Equal(dst, dstGeneric) {
+			test.Fatal("aes128MMO differs")
+		}
+		// in-place use as in the DPF
+		aes128MMOGeneric(&xk[0], &src[0], &src[0])
+		if !bytes.Equal(src, dst) {
+			test.Fatal("in-place aes128MMO differs")
+		}
+
+		xor16(&dst[0], &key[0], &expected[0])
+		xor16Generic(&dstGeneric[0], &key[0], &expected[0])
+		if !bytes.Equal(dst, dstGeneric) {
+			test.Fatal("xor16 differs")
+		}
+	}
+}
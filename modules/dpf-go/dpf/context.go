@@ -0,0 +1,79 @@
+package dpf
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+/*
+Context holds the two fixed AES keys of the PRG that expands the GGM tree.
+Keys generated with a context can only be evaluated with a context using the same PRG keys,
+so a deployment can use its own keys and several contexts can coexist in one process.
+The package-level functions use a default context with fixed public keys.
+*/
+type Context struct {
+	keyL []uint32 // expanded round keys of the left PRG half
+	keyR []uint32 // expanded round keys of the right PRG half
+}
+
+// NewContext creates a context from two 16-byte AES keys
+func NewContext(prfKeyL, prfKeyR []byte) (*Context, error) {
+	if len(prfKeyL) != 16 || len(prfKeyR) != 16 {
+		return nil, errors.New("dpf: PRG keys must be 16 bytes")
+	}
+	c := &Context{make([]uint32, 11*4), make([]uint32, 11*4)}
+	expandKeyAsm(&prfKeyL[0], &c.keyL[0])
+	expandKeyAsm(&prfKeyR[0], &c.keyR[0])
+	return c, nil
+}
+
+// Gen generates the keys of a DPF for the point alpha with seeds from crypto/rand
+func (c *Context) Gen(alpha uint64, logN uint64) (DPFkey, DPFkey) {
+	return c.GenWithReader(alpha, logN, rand.Reader)
+}
+
+func Gen(alpha uint64, logN uint64) (DPFkey, DPFkey) {
+	return defaultContext.Gen(alpha, logN)
+}
+
+// Like Gen, but the seeds are read from r, e.g. a seeded PRG for reproducible keys
+func GenWithReader(alpha uint64, logN uint64, r io.Reader) (DPFkey, DPFkey) {
+	return defaultContext.GenWithReader(alpha, logN, r)
+}
+
+func Eval(k DPFkey, x uint64, logN uint64) byte {
+	return defaultContext.Eval(k, x, logN)
+}
+
+func EvalFull(key DPFkey, logN uint64) []byte {
+	return defaultContext.EvalFull(key, logN)
+}
+
+func EvalFullN(key DPFkey, logN uint64, n uint64) []byte {
+	return defaultContext.EvalFullN(key, logN, n)
+}
+
+func EvalFullStream(key DPFkey, logN uint64, fn func(blockIdx uint64, leaves []byte)) {
+	defaultContext.EvalFullStream(key, logN, fn)
+}
+
+func EvalFullStreamN(key DPFkey, logN uint64, n uint64, fn func(blockIdx uint64, leaves []byte)) {
+	defaultContext.EvalFullStreamN(key, logN, n, fn)
+}
+
+func EvalRange(key DPFkey, logN uint64, from, to uint64) []byte {
+	return defaultContext.EvalRange(key, logN, from, to)
+}
+
+func EvalFullParallel(key DPFkey, logN uint64, splitDepth uint64, numWorkers int) []byte {
+	return defaultContext.EvalFullParallel(key, logN, splitDepth, numWorkers)
+}
+
+func EvalFullParallelN(key DPFkey, logN uint64, n uint64, splitDepth uint64, numWorkers int) []byte {
+	return defaultContext.EvalFullParallelN(key, logN, n, splitDepth, numWorkers)
+}
+
+func GenWithLeafBits(alpha uint64, logN uint64, leafBits int, r io.Reader) (DPFkey, DPFkey) {
+	return defaultContext.GenWithLeafBits(alpha, logN, leafBits, r)
+}
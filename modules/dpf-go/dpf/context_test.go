@@ -0,0 +1,54 @@
+package dpf
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestGenWithReader(test *testing.T) {
+	a1, b1 := GenWithReader(77, 10, rand.New(rand.NewSource(1)))
+	a2, b2 := GenWithReader(77, 10, rand.New(rand.NewSource(1)))
+	if !bytes.Equal(a1, a2) || !bytes.Equal(b1, b2) {
+		test.Fatal("keys from the same randomness differ")
+	}
+	a3, _ := GenWithReader(77, 10, rand.New(rand.NewSource(2)))
+	if bytes.Equal(a1, a3) {
+		test.Fatal("keys from different randomness are equal")
+	}
+}
+
+func TestContext(test *testing.T) {
+	ctx, err := NewContext(bytes.Repeat([]byte{1}, 16), bytes.Repeat([]byte{2}, 16))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if _, err := NewContext(make([]byte, 15), make([]byte, 16)); err == nil {
+		test.Fatal("short PRG key was accepted")
+	}
+	logN := uint64(10)
+	alpha := uint64(300)
+	a, b := ctx.Gen(alpha, logN)
+	outA, outB := ctx.EvalFull(a, logN), ctx.EvalFull(b, logN)
+	for i := uint64(0); i < 1<<logN; i++ {
+		bit := ((outA[i/8] ^ outB[i/8]) >> (i % 8)) & 1
+		if (i == alpha) != (bit == 1) || ctx.Eval(a, i, logN)^ctx.Eval(b, i, logN) != bit {
+			test.Fatalf("wrong output at %d", i)
+		}
+	}
+	// the same seeds give different keys in another context
+	a1, _ := ctx.GenWithReader(alpha, logN, rand.New(rand.NewSource(1)))
+	a2, _ := GenWithReader(alpha, logN, rand.New(rand.NewSource(1)))
+	if bytes.Equal(a1, a2) {
+		test.Fatal("keys of different contexts are equal")
+	}
+
+	pa, pb := ctx.GenPayload(3, 4, []byte("payload"), GroupXOR)
+	data, _ := pb.MarshalBinary()
+	if pb, err = ctx.ParsePayloadKey(data); err != nil {
+		test.Fatal(err)
+	}
+	if !bytes.Equal(GroupXOR.Combine(pa.Eval(3), pb.Eval(3)), []byte("payload")) {
+		test.Fatal("wrong payload output")
+	}
+}
@@ -1,7 +1,9 @@
 package dpf
 
 import (
-	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sync"
 )
 
 type DPFkey []byte
@@ -12,27 +14,19 @@ type bytearr struct {
 	index uint64
 }
 
-var prfL *aesPrf
-var prfR *aesPrf
-var keyL = make([]uint32, 11*4)
-var keyR = make([]uint32, 11*4)
-
 //var blockStack = make([][2]*block, 63)
 
+// context with the fixed PRG keys used by the package-level functions
+var defaultContext *Context
+
 func init() {
 	var prfkeyL = []byte{36, 156, 50, 234, 92, 230, 49, 9, 174, 170, 205, 160, 98, 236, 29, 243}
 	var prfkeyR = []byte{209, 12, 199, 173, 29, 74, 44, 128, 194, 224, 14, 44, 2, 201, 110, 28}
-	var errL, errR error
-	prfL, errL = newCipher(prfkeyL)
-	if errL != nil {
+	var err error
+	defaultContext, err = NewContext(prfkeyL, prfkeyR)
+	if err != nil {
 		panic("dpf: can't init AES")
 	}
-	prfR, errR = newCipher(prfkeyR)
-	if errR != nil {
-		panic("dpf: can't init AES")
-	}
-	expandKeyAsm(&prfkeyL[0], &keyL[0])
-	expandKeyAsm(&prfkeyR[0], &keyR[0])
 	//if cpu.X86.HasSSE2 == false || cpu.X86.HasAVX2 == false {
 	//	panic("we need sse2 and avx")
 	//}
@@ -51,34 +45,84 @@ func clr(in *byte) {
 	*in &^= 0x1
 }
 
-func convertBlock(in []byte) {
+func (c *Context) convertBlock(in []byte) {
 	//prfL.Encrypt(in, in)
-	aes128MMO(&keyL[0], &in[0], &in[0])
+	aes128MMO(&c.keyL[0], &in[0], &in[0])
 }
 
-func prg(seed, s0, s1 *byte) (byte, byte) {
+func (c *Context) prg(seed, s0, s1 *byte) (byte, byte) {
 	//prfL.Encrypt(s0, seed)
-	aes128MMO(&keyL[0], s0, seed)
+	aes128MMO(&c.keyL[0], s0, seed)
 	t0 := getT(s0)
 	clr(s0)
 	//prfR.Encrypt(s1, seed)
-	aes128MMO(&keyR[0], s1, seed)
+	aes128MMO(&c.keyR[0], s1, seed)
 	t1 := getT(s1)
 	clr(s1)
 	return t0, t1
 }
 
-func Gen(alpha uint64, logN uint64) (DPFkey, DPFkey) {
-	if alpha >= (1<<logN) || logN > 63 {
+func (c *Context) GenWithReader(alpha uint64, logN uint64, r io.Reader) (DPFkey, DPFkey) {
+	return c.GenWithLeafBits(alpha, logN, DefaultLeafBits, r)
+}
+
+/*
+GenWithLeafBits generates keys whose tree stops leafBits/128 levels above the leaves (early termination),
+each remaining leaf is then stretched to leafBits outputs with leafBits/128 AES calls.
+Wider leaves give shorter keys and fewer AES calls in EvalFull, but more AES calls in Eval.
+leafBits is one of 128, 256, 512 or 1024, the width is stored in the key (see LeafBits and Encode).
+*/
+func (c *Context) GenWithLeafBits(alpha uint64, logN uint64, leafBits int, r io.Reader) (DPFkey, DPFkey) {
+	leafLog, ok := leafBitsLog(leafBits)
+	if alpha >= (1<<logN) || logN > 63 || !ok {
 		panic("dpf: invalid parameters")
 	}
+	ka, kb, s0, s1, _, _ := c.genTree(alpha, logN, treeDepth(logN, leafLog), r)
+	ka[16] |= byte(leafLog) << 1
+	kb[16] |= byte(leafLog) << 1
+	scw := new(block)
+	ss0 := new(block)
+	ss1 := new(block)
+	leafMask := uint64(128)<<leafLog - 1
+	for j := uint64(0); j < 1<<leafLog; j++ {
+		//convertBlock(s0[:])
+		c.convertLeafBlock(ss0, s0, j)
+		//convertBlock(s1[:])
+		c.convertLeafBlock(ss1, s1, j)
+		xor16(&scw[0], &ss0[0], &ss1[0])
+		if (alpha&leafMask)/128 == j {
+			scw[(alpha&127)/8] ^= byte(1) << ((alpha & 127) % 8)
+		}
+		ka = append(ka, scw[:]...)
+		kb = append(kb, scw[:]...)
+	}
+	return ka, kb
+}
+
+// computes block j of the leaf with seed s, block 0 is AES-MMO of the seed,
+// for the following blocks j is XORed into the seed before
+func (c *Context) convertLeafBlock(out *block, s *block, j uint64) {
+	*out = *s
+	if j != 0 {
+		binary.LittleEndian.PutUint64(out[8:], binary.LittleEndian.Uint64(out[8:])^j)
+	}
+	aes128MMO(&c.keyL[0], &out[0], &out[0])
+}
+
+// generates the first stop levels of the keys for the point alpha in a domain of size 2^logN,
+// i.e. seeds, control bits and correction words, and returns the seeds and control bits
+// of both parties on the path to alpha at level stop. The initial seeds are read from r.
+func (c *Context) genTree(alpha uint64, logN uint64, stop uint64, r io.Reader) (DPFkey, DPFkey, *block, *block, byte, byte) {
 	var ka, kb DPFkey
-	var CW []byte
 	s0 := new(block)
 	s1 := new(block)
 	scw := new(block)
-	rand.Read(s0[:])
-	rand.Read(s1[:])
+	if _, err := io.ReadFull(r, s0[:]); err != nil {
+		panic("dpf: failed to read randomness")
+	}
+	if _, err := io.ReadFull(r, s1[:]); err != nil {
+		panic("dpf: failed to read randomness")
+	}
 
 	t0 := getT(&s0[0])
 	t1 := t0 ^ 1
@@ -91,25 +135,23 @@ func Gen(alpha uint64, logN uint64) (DPFkey, DPFkey) {
 	kb = append(kb, s1[:]...)
 	kb = append(kb, t1)
 
-	stop := uint64(0)
-	if logN >= 7 {
-		stop = logN - 7
-	}
 	s0L := new(block)
 	s0R := new(block)
 	s1L := new(block)
 	s1R := new(block)
 	for i := uint64(0); i < stop; i++ {
-		t0L, t0R := prg(&s0[0], &s0L[0], &s0R[0])
-		t1L, t1R := prg(&s1[0], &s1L[0], &s1R[0])
+		t0L, t0R := c.prg(&s0[0], &s0L[0], &s0R[0])
+		t1L, t1R := c.prg(&s1[0], &s1L[0], &s1R[0])
 
 		if (alpha & (1 << (logN - 1 - i))) != 0 {
 			//KEEP = R, LOSE = L
 			xor16(&scw[0], &s0L[0], &s1L[0])
 			tLCW := t0L ^ t1L
 			tRCW := t0R ^ t1R ^ 1
-			CW = append(CW, scw[:]...)
-			CW = append(CW, tLCW, tRCW)
+			ka = append(ka, scw[:]...)
+			ka = append(ka, tLCW, tRCW)
+			kb = append(kb, scw[:]...)
+			kb = append(kb, tLCW, tRCW)
 			*s0 = *s0R
 			if t0 != 0 {
 				xor16(&s0[0], &s0[0], &scw[0])
@@ -134,8 +176,10 @@ func Gen(alpha uint64, logN uint64) (DPFkey, DPFkey) {
 			xor16(&scw[0], &s0R[0], &s1R[0])
 			tLCW := t0L ^ t1L ^ 1
 			tRCW := t0R ^ t1R
-			CW = append(CW, scw[:]...)
-			CW = append(CW, tLCW, tRCW)
+			ka = append(ka, scw[:]...)
+			ka = append(ka, tLCW, tRCW)
+			kb = append(kb, scw[:]...)
+			kb = append(kb, tLCW, tRCW)
 			*s0 = *s0L
 			if t0 != 0 {
 				xor16(&s0[0], &s0[0], &scw[0])
@@ -156,32 +200,21 @@ func Gen(alpha uint64, logN uint64) (DPFkey, DPFkey) {
 			}
 		}
 	}
-	//convertBlock(s0[:])
-	aes128MMO(&keyL[0], &s0[0], &s0[0])
-	//convertBlock(s1[:])
-	aes128MMO(&keyL[0], &s1[0], &s1[0])
-	xor16(&scw[0], &s0[0], &s1[0])
-	scw[(alpha&127)/8] ^= byte(1) << ((alpha & 127) % 8)
-	CW = append(CW, scw[:]...)
-	ka = append(ka, CW...)
-	kb = append(kb, CW...)
-	return ka, kb
+	return ka, kb, s0, s1, t0, t1
 }
 
-func Eval(k DPFkey, x uint64, logN uint64) byte {
+func (c *Context) Eval(k DPFkey, x uint64, logN uint64) byte {
 	s := new(block)
 	sL := new(block)
 	sR := new(block)
 	copy(s[:], k[:16])
-	t := k[16]
+	t := keyT(k)
 
-	stop := uint64(0)
-	if logN >= 7 {
-		stop = logN - 7
-	}
+	leafLog := keyLeafLog(k)
+	stop := treeDepth(logN, leafLog)
 
 	for i := uint64(0); i < stop; i++ {
-		tL, tR := prg(&s[0], &sL[0], &sR[0])
+		tL, tR := c.prg(&s[0], &sL[0], &sR[0])
 		if t != 0 {
 			sCW := k[17+i*18 : 17+i*18+16]
 			tLCW := k[17+i*18+16]
@@ -201,32 +234,43 @@ func Eval(k DPFkey, x uint64, logN uint64) byte {
 	}
 	//fmt.Println("Debug", s, t)
 	//convertBlock(s[:])
-	aes128MMO(&keyL[0], &s[0], &s[0])
+	j := (x >> 7) & (1<<leafLog - 1)
+	c.convertLeafBlock(s, s, j)
 	if t != 0 {
-		xor16(&s[0], &s[0], &k[len(k)-16])
+		xor16(&s[0], &s[0], &k[len(k)-(16<<leafLog)+16*int(j)])
 		return (s[(x&127)/8] >> ((x & 127) % 8)) & 1
 	} else {
 		return (s[(x&127)/8] >> ((x & 127) % 8)) & 1
 	}
 }
 
-func evalFullRecursive(blockStack [][2]*block, k DPFkey, s *block, t byte, lvl uint64, stop uint64, res *bytearr) {
+// expands the subtree below s and calls emit for each 128-bit leaf block in [first, last), in order.
+// idx is the index of the node at level lvl, the tree ends at level stop with leaves of 2^leafLog blocks,
+// so the leaf blocks are numbered from 0 to 2^(stop+leafLog)-1.
+// Subtrees without leaf blocks in [first, last) are skipped.
+func (c *Context) evalFullRecursive(blockStack [][2]*block, k DPFkey, s *block, t byte, lvl uint64, stop uint64, leafLog uint64, idx uint64, first, last uint64, emit func(uint64, *block)) {
+	if idx<<(stop-lvl+leafLog) >= last || (idx+1)<<(stop-lvl+leafLog) <= first {
+		return
+	}
 	if lvl == stop {
 		ss := blockStack[lvl][0]
-		*ss = *s
-		aes128MMO(&keyL[0], &ss[0], &ss[0])
-		if t != 0 {
-			xor16(&res.data[res.index], &ss[0], &k[len(k)-16])
-			res.index += 16
-		} else {
-			xor16(&res.data[res.index], &ss[0], &res.data[res.index])
-			res.index += 16
+		cw := k[len(k)-(16<<leafLog):]
+		for j := uint64(0); j < 1<<leafLog; j++ {
+			b := idx<<leafLog + j
+			if b < first || b >= last {
+				continue
+			}
+			c.convertLeafBlock(ss, s, j)
+			if t != 0 {
+				xor16(&ss[0], &ss[0], &cw[16*j])
+			}
+			emit(b, ss)
 		}
 		return
 	}
 	sL := blockStack[lvl][0]
 	sR := blockStack[lvl][1]
-	tL, tR := prg(&s[0], &sL[0], &sR[0])
+	tL, tR := c.prg(&s[0], &sL[0], &sR[0])
 	if t != 0 {
 		sCW := k[17+lvl*18 : 17+lvl*18+16]
 		tLCW := k[17+lvl*18+16]
@@ -236,27 +280,192 @@ func evalFullRecursive(blockStack [][2]*block, k DPFkey, s *block, t byte, lvl u
 		tL ^= tLCW
 		tR ^= tRCW
 	}
-	evalFullRecursive(blockStack, k, sL, tL, lvl+1, stop, res)
-	evalFullRecursive(blockStack, k, sR, tR, lvl+1, stop, res)
+	c.evalFullRecursive(blockStack, k, sL, tL, lvl+1, stop, leafLog, 2*idx, first, last, emit)
+	c.evalFullRecursive(blockStack, k, sR, tR, lvl+1, stop, leafLog, 2*idx+1, first, last, emit)
+}
+
+func newBlockStack() [][2]*block {
+	var blockStack = make([][2]*block, 64)
+	for i := range blockStack {
+		blockStack[i][0] = new(block)
+		blockStack[i][1] = new(block)
+	}
+	return blockStack
+}
+
+// DomainBits returns the number of input bits needed for a domain of n leaves, i.e. ceil(log2(n))
+func DomainBits(n uint64) uint64 {
+	logN := uint64(0)
+	for logN < 64 && (uint64(1)<<logN) < n {
+		logN++
+	}
+	return logN
+}
+
+// number of 128-leaf blocks needed to hold n leaves
+func numBlocks(n uint64) uint64 {
+	if n == 0 {
+		return 0
+	}
+	return (n-1)/128 + 1
+}
+
+func (c *Context) EvalFull(key DPFkey, logN uint64) []byte {
+	return c.EvalFullN(key, logN, 1<<logN)
+}
+
+// EvalFullN expands the first n leaves of a domain of size 2^logN.
+// Subtrees right of leaf n are not expanded, so a domain that is not a power of two
+// only pays for the leaves it uses. The output is padded to whole blocks of 128 leaves,
+// bits beyond leaf n are not defined.
+func (c *Context) EvalFullN(key DPFkey, logN uint64, n uint64) []byte {
+	buf := make([]byte, 16*numBlocks(n))
+	c.EvalFullStreamN(key, logN, n, func(idx uint64, leaves []byte) {
+		copy(buf[16*idx:], leaves)
+	})
+	return buf
+}
+
+// EvalFullStream expands the whole domain like EvalFull, but hands each block of 128 leaves
+// (16 bytes, leaf i of the block is bit i%8 of byte i/8) to fn as soon as it is computed,
+// so memory stays constant independent of logN.
+// fn is called in leaf order with the index of the block, the slice is only valid during the call.
+// For logN < 7, fn is called once and only the first 2^logN bits are defined.
+func (c *Context) EvalFullStream(key DPFkey, logN uint64, fn func(blockIdx uint64, leaves []byte)) {
+	c.EvalFullStreamN(key, logN, 1<<logN, fn)
+}
+
+// Like EvalFullStream, but only the blocks holding the first n leaves are expanded
+func (c *Context) EvalFullStreamN(key DPFkey, logN uint64, n uint64, fn func(blockIdx uint64, leaves []byte)) {
+	if n > (1<<logN) || logN > 63 {
+		panic("dpf: invalid parameters")
+	}
+	s := new(block)
+	copy(s[:], key[:16])
+	t := keyT(key)
+	leafLog := keyLeafLog(key)
+	c.evalFullRecursive(newBlockStack(), key, s, t, 0, treeDepth(logN, leafLog), leafLog, 0, 0, numBlocks(n), func(idx uint64, b *block) {
+		fn(idx, b[:])
+	})
 }
 
-func EvalFull(key DPFkey, logN uint64) []byte {
+// EvalRange expands only the leaves in [from, to), e.g. for a server holding a shard of the database.
+// Bit i of the output (bit i%8 of byte i/8) is the output at leaf from+i, unused bits of the last byte are 0.
+// Only the subtrees covering the interval are expanded.
+func (c *Context) EvalRange(key DPFkey, logN uint64, from, to uint64) []byte {
+	if from > to || to > (1<<logN) || logN > 63 {
+		panic("dpf: invalid parameters")
+	}
+	out := make([]byte, (to-from+7)/8)
+	if from == to {
+		return out
+	}
 	s := new(block)
 	copy(s[:], key[:16])
-	t := key[16]
-	stop := uint64(0)
-	buf := make([]byte, 16)
-	if logN >= 7 {
-		stop = logN - 7
-		buf = make([]byte, 1<<(logN-3))
+	t := keyT(key)
+	leafLog := keyLeafLog(key)
+	c.evalFullRecursive(newBlockStack(), key, s, t, 0, treeDepth(logN, leafLog), leafLog, 0, from/128, (to+127)/128, func(idx uint64, b *block) {
+		lo, hi := idx*128, idx*128+128
+		if lo < from {
+			lo = from
+		}
+		if hi > to {
+			hi = to
+		}
+		copyBits(out, lo-from, b[:], lo-idx*128, hi-lo)
+	})
+	return out
+}
+
+// copies n bits from src starting at bit srcOff to dst starting at bit dstOff
+func copyBits(dst []byte, dstOff uint64, src []byte, srcOff uint64, n uint64) {
+	// whole bytes can be copied if both offsets are byte aligned
+	if dstOff%8 == 0 && srcOff%8 == 0 {
+		copy(dst[dstOff/8:], src[srcOff/8:(srcOff+n)/8])
+		dstOff, srcOff, n = dstOff+n&^7, srcOff+n&^7, n%8
+	}
+	for i := uint64(0); i < n; i++ {
+		bit := (src[(srcOff+i)/8] >> ((srcOff + i) % 8)) & 1
+		dst[(dstOff+i)/8] |= bit << ((dstOff + i) % 8)
 	}
+}
 
-	var b = bytearr{buf, 0}
-	var blockStack = make([][2]*block, 63)
-	for i := 0; i < 63; i++ {
-		blockStack[i][0] = new(block)
-		blockStack[i][1] = new(block)
+// seed and control bit of a node in the GGM tree
+type node struct {
+	s block
+	t byte
+}
+
+// computes both children of a node at level lvl
+func (c *Context) expandNode(k DPFkey, n *node, lvl uint64) (node, node) {
+	var l, r node
+	l.t, r.t = c.prg(&n.s[0], &l.s[0], &r.s[0])
+	if n.t != 0 {
+		sCW := k[17+lvl*18 : 17+lvl*18+16]
+		xor16(&l.s[0], &l.s[0], &sCW[0])
+		xor16(&r.s[0], &r.s[0], &sCW[0])
+		l.t ^= k[17+lvl*18+16]
+		r.t ^= k[17+lvl*18+17]
+	}
+	return l, r
+}
+
+// EvalFullParallel computes the same output as EvalFull on multiple cores.
+// The tree is expanded sequentially down to splitDepth, the resulting 2^splitDepth subtrees
+// are then expanded by numWorkers goroutines, each writing into its own part of the output.
+// splitDepth is capped at the depth of the tree.
+func (c *Context) EvalFullParallel(key DPFkey, logN uint64, splitDepth uint64, numWorkers int) []byte {
+	return c.EvalFullParallelN(key, logN, 1<<logN, splitDepth, numWorkers)
+}
+
+// Like EvalFullParallel, but only the first n leaves are expanded as in EvalFullN
+func (c *Context) EvalFullParallelN(key DPFkey, logN uint64, n uint64, splitDepth uint64, numWorkers int) []byte {
+	if n > (1<<logN) || logN > 63 {
+		panic("dpf: invalid parameters")
+	}
+	leafLog := keyLeafLog(key)
+	stop := treeDepth(logN, leafLog)
+	last := numBlocks(n)
+	buf := make([]byte, 16*last)
+	if splitDepth > stop {
+		splitDepth = stop
+	}
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	nodes := []node{{t: keyT(key)}}
+	copy(nodes[0].s[:], key[:16])
+	for lvl := uint64(0); lvl < splitDepth; lvl++ {
+		next := make([]node, 0, 2*len(nodes))
+		for i := range nodes {
+			l, r := c.expandNode(key, &nodes[i], lvl)
+			// subtrees starting beyond the last block are never needed, the kept nodes
+			// are a prefix of the level so the index in nodes is still the node index
+			if uint64(2*i)<<(stop-lvl-1+leafLog) < last {
+				next = append(next, l)
+			}
+			if uint64(2*i+1)<<(stop-lvl-1+leafLog) < last {
+				next = append(next, r)
+			}
+		}
+		nodes = next
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			blockStack := newBlockStack()
+			emit := func(idx uint64, b *block) {
+				copy(buf[16*idx:], b[:])
+			}
+			for i := w; i < len(nodes); i += numWorkers {
+				c.evalFullRecursive(blockStack, key, &nodes[i].s, nodes[i].t, splitDepth, stop, leafLog, uint64(i), 0, last, emit)
+			}
+		}(w)
 	}
-	evalFullRecursive(blockStack,key, s, t, 0, stop, &b)
-	return b.data
+	wg.Wait()
+	return buf
 }
@@ -1,7 +1,10 @@
 package dpf
 
 import (
+	"bytes"
+	"crypto/rand"
 	"fmt"
+	"runtime"
 	"testing"
 )
 
@@ -73,6 +76,163 @@ func TestEvalFullShort(test *testing.T) {
 	}
 }
 
+func TestEvalFullStream(test *testing.T) {
+	logN := uint64(10)
+	alpha := uint64(777)
+	a, b := Gen(alpha, logN)
+	next := uint64(0)
+	aa := make([]byte, 0, 1<<(logN-3))
+	EvalFullStream(a, logN, func(idx uint64, leaves []byte) {
+		if idx != next || len(leaves) != 16 {
+			test.Fatal("blocks out of order")
+		}
+		next++
+		aa = append(aa, leaves...)
+	})
+	bb := EvalFull(b, logN)
+	for i := uint64(0); i < (uint64(1) << logN); i++ {
+		aaa := (aa[i/8] >> (i % 8)) & 1
+		bbb := (bb[i/8] >> (i % 8)) & 1
+		if (aaa^bbb == 1 && i != alpha) || (aaa^bbb == 0 && i == alpha) {
+			test.Fail()
+		}
+	}
+}
+
+func TestEvalFullParallel(test *testing.T) {
+	for _, logN := range []uint64{3, 7, 12} {
+		alpha := uint64(5)
+		a, _ := Gen(alpha, logN)
+		expected := EvalFull(a, logN)
+		for _, splitDepth := range []uint64{0, 1, 3, 10} {
+			for _, workers := range []int{1, 3, 8} {
+				if !bytes.Equal(EvalFullParallel(a, logN, splitDepth, workers), expected) {
+					test.Fatalf("parallel evaluation differs for logN=%d, splitDepth=%d, workers=%d", logN, splitDepth, workers)
+				}
+			}
+		}
+	}
+}
+
+func TestEvalFullN(test *testing.T) {
+	for _, n := range []uint64{1, 5, 128, 129, 1000, 4096, 4097, 1 << 13} {
+		logN := DomainBits(n)
+		if n > 1 && (uint64(1)<<(logN-1) >= n || uint64(1)<<logN < n) {
+			test.Fatalf("wrong domain bits %d for n=%d", logN, n)
+		}
+		alpha := n - 1
+		a, b := Gen(alpha, logN)
+		full := EvalFull(a, logN)
+		outA := EvalFullN(a, logN, n)
+		outB := EvalFullN(b, logN, n)
+		if len(outA) != 16*int((n+127)/128) {
+			test.Fatalf("wrong output length %d for n=%d", len(outA), n)
+		}
+		if !bytes.Equal(outA, full[:len(outA)]) {
+			test.Fatalf("EvalFullN differs from EvalFull for n=%d", n)
+		}
+		for _, workers := range []int{1, 3} {
+			if !bytes.Equal(EvalFullParallelN(a, logN, n, 4, workers), outA) {
+				test.Fatalf("EvalFullParallelN differs from EvalFullN for n=%d, workers=%d", n, workers)
+			}
+		}
+		for i := uint64(0); i < n; i++ {
+			bit := ((outA[i/8] ^ outB[i/8]) >> (i % 8)) & 1
+			if (i == alpha) != (bit == 1) {
+				test.Fatalf("wrong output at %d for n=%d", i, n)
+			}
+		}
+	}
+}
+
+func TestLeafBits(test *testing.T) {
+	for _, leafBits := range []int{128, 256, 512, 1024} {
+		for _, logN := range []uint64{3, 8, 9, 10, 13} {
+			for _, alpha := range []uint64{0, 1<<logN - 1, 5 * (1 << logN) / 7} {
+				a, b := GenWithLeafBits(alpha, logN, leafBits, rand.Reader)
+				if LeafBits(a) != leafBits {
+					test.Fatalf("wrong leaf width %d, expected %d", LeafBits(a), leafBits)
+				}
+				outA, outB := EvalFull(a, logN), EvalFull(b, logN)
+				for i := uint64(0); i < 1<<logN; i++ {
+					bit := ((outA[i/8] ^ outB[i/8]) >> (i % 8)) & 1
+					if (i == alpha) != (bit == 1) {
+						test.Fatalf("wrong output at %d (leafBits %d, logN %d, alpha %d)", i, leafBits, logN, alpha)
+					}
+					if i%37 == 0 || i == alpha {
+						if Eval(a, i, logN) != (outA[i/8]>>(i%8))&1 {
+							test.Fatalf("Eval and EvalFull differ at %d (leafBits %d, logN %d)", i, leafBits, logN)
+						}
+					}
+				}
+				n := uint64(1)<<logN - 100
+				if logN < 8 {
+					n = 1 << logN
+				}
+				outN := EvalFullN(a, logN, n)
+				if !bytes.Equal(outN, outA[:len(outN)]) || !bytes.Equal(EvalFullParallelN(a, logN, n, 3, 2), outN) {
+					test.Fatalf("EvalFullN differs from EvalFull (leafBits %d, logN %d)", leafBits, logN)
+				}
+				r := EvalRange(a, logN, 1<<logN/3, 1<<logN-1)
+				for i := uint64(0); i < uint64(len(r))*8 && 1<<logN/3+i < 1<<logN-1; i++ {
+					if (r[i/8]>>(i%8))&1 != (outA[(1<<logN/3+i)/8]>>((1<<logN/3+i)%8))&1 {
+						test.Fatalf("EvalRange differs from EvalFull (leafBits %d, logN %d)", leafBits, logN)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkEvalFullLeafBits(bench *testing.B) {
+	logN := uint64(20)
+	for _, leafBits := range []int{128, 256, 512, 1024} {
+		a, _ := GenWithLeafBits(123, logN, leafBits, rand.Reader)
+		bench.Run(fmt.Sprintf("leafBits=%d", leafBits), func(bench *testing.B) {
+			for i := 0; i < bench.N; i++ {
+				EvalFull(a, logN)
+			}
+		})
+	}
+}
+
+func TestEvalRange(test *testing.T) {
+	for _, logN := range []uint64{3, 7, 11} {
+		alpha := uint64(6)
+		a, _ := Gen(alpha, logN)
+		full := EvalFull(a, logN)
+		n := uint64(1) << logN
+		ranges := [][2]uint64{{0, n}, {0, 0}, {0, 1}, {n - 1, n}, {3, 5}, {0, n / 2}, {n / 2, n}}
+		if logN > 7 {
+			ranges = append(ranges, [2]uint64{128, 256}, [2]uint64{100, 1000}, [2]uint64{131, 1617}, [2]uint64{1000, 1001})
+		}
+		for _, r := range ranges {
+			out := EvalRange(a, logN, r[0], r[1])
+			if len(out) != int((r[1]-r[0]+7)/8) {
+				test.Fatalf("wrong output length for range %v", r)
+			}
+			for i := uint64(0); i < r[1]-r[0]; i++ {
+				if (out[i/8]>>(i%8))&1 != (full[(r[0]+i)/8]>>((r[0]+i)%8))&1 {
+					test.Fatalf("range %v differs from EvalFull at leaf %d", r, r[0]+i)
+				}
+			}
+			for i := r[1] - r[0]; i < uint64(8*len(out)); i++ {
+				if (out[i/8]>>(i%8))&1 != 0 {
+					test.Fatalf("unused bits of range %v are not 0", r)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkEvalFullParallel(bench *testing.B) {
+	logN := uint64(28)
+	a, _ := Gen(0, logN)
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		EvalFullParallel(a, logN, 6, runtime.NumCPU())
+	}
+}
 
 func DebugAES(test *testing.T) { 
 	var prfkeyL = []byte{36, 156, 50, 234, 92, 230, 49, 9, 174, 170, 205, 160, 98, 236, 29, 243}
@@ -0,0 +1,135 @@
+package dpf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// versions of the key encoding produced by Encode
+const (
+	// version(1) | logN(1) | key, for keys with 128-bit leaves
+	KeyVersion = 1
+	// version(1) | logN(1) | depth(1) | key, depth is the early-termination depth log2(leaf bits)
+	KeyVersionLeafBits = 2
+)
+
+const (
+	DefaultLeafBits = 128
+	MaxLeafBits     = 1024
+)
+
+// errors returned by ParseKey, the returned errors wrap one of these
+var (
+	ErrKeyVersion = errors.New("dpf: unsupported key version")
+	ErrKeyDomain  = errors.New("dpf: key has wrong domain size")
+	ErrKeyDepth   = errors.New("dpf: unsupported early termination depth")
+	ErrKeyLength  = errors.New("dpf: key has wrong length")
+)
+
+// returns log2(leafBits/128) if leafBits is a supported leaf width
+func leafBitsLog(leafBits int) (uint64, bool) {
+	for leafLog := uint64(0); 128<<leafLog <= MaxLeafBits; leafLog++ {
+		if 128<<leafLog == leafBits {
+			return leafLog, true
+		}
+	}
+	return 0, false
+}
+
+// number of tree levels above the leaves of 2^leafLog blocks of 128 bits
+func treeDepth(logN, leafLog uint64) uint64 {
+	if logN >= 7+leafLog {
+		return logN - 7 - leafLog
+	}
+	return 0
+}
+
+func keyLen(logN, leafLog uint64) int {
+	return 17 + 18*int(treeDepth(logN, leafLog)) + 16<<leafLog
+}
+
+// KeyLen returns the length of a DPFkey (without encoding header) for a domain of size 2^logN
+func KeyLen(logN uint64) int {
+	return keyLen(logN, 0)
+}
+
+// Like KeyLen, for keys generated with GenWithLeafBits
+func KeyLenWithLeafBits(logN uint64, leafBits int) int {
+	leafLog, ok := leafBitsLog(leafBits)
+	if !ok {
+		panic("dpf: invalid parameters")
+	}
+	return keyLen(logN, leafLog)
+}
+
+/*
+The leaf width is stored in the key: byte 16 holds the control bit of the root in bit 0
+and log2(leafBits/128) above it, so keys with 128-bit leaves are unchanged.
+*/
+func keyLeafLog(k DPFkey) uint64 {
+	return uint64(k[16] >> 1)
+}
+
+// control bit of the root of a key
+func keyT(k DPFkey) byte {
+	return k[16] & 1
+}
+
+// LeafBits returns the leaf width stored in a key
+func LeafBits(k DPFkey) int {
+	return 128 << keyLeafLog(k)
+}
+
+// Encode returns the wire encoding of a key for a domain of size 2^logN,
+// keys with 128-bit leaves are encoded with KeyVersion, wider leaves with KeyVersionLeafBits
+func (k DPFkey) Encode(logN uint64) []byte {
+	leafLog := keyLeafLog(k)
+	if leafLog == 0 {
+		out := make([]byte, 2, 2+len(k))
+		out[0] = KeyVersion
+		out[1] = byte(logN)
+		return append(out, k...)
+	}
+	out := make([]byte, 3, 3+len(k))
+	out[0] = KeyVersionLeafBits
+	out[1] = byte(logN)
+	out[2] = byte(7 + leafLog)
+	return append(out, k...)
+}
+
+// ParseKey decodes a key encoded with Encode and checks that it is a well-formed key
+// for a domain of size 2^logN, so it can safely be passed to Eval and EvalFull.
+// The returned key shares its memory with data.
+func ParseKey(data []byte, logN uint64) (DPFkey, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrKeyLength, len(data))
+	}
+	var leafLog uint64
+	header := 2
+	switch data[0] {
+	case KeyVersion:
+	case KeyVersionLeafBits:
+		if len(data) < 3 {
+			return nil, fmt.Errorf("%w: %d bytes", ErrKeyLength, len(data))
+		}
+		var ok bool
+		if leafLog, ok = leafBitsLog(1 << data[2]); !ok {
+			return nil, fmt.Errorf("%w: %d", ErrKeyDepth, data[2])
+		}
+		header = 3
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrKeyVersion, data[0])
+	}
+	if uint64(data[1]) != logN {
+		return nil, fmt.Errorf("%w: got 2^%d, expected 2^%d", ErrKeyDomain, data[1], logN)
+	}
+	if len(data)-header != keyLen(logN, leafLog) {
+		return nil, fmt.Errorf("%w: got %d bytes, expected %d", ErrKeyLength, len(data)-header, keyLen(logN, leafLog))
+	}
+	// the leaf width in the key has to match the header, evaluation relies on it
+	k := DPFkey(data[header:])
+	if keyLeafLog(k) != leafLog {
+		return nil, fmt.Errorf("%w: key has leaves of %d bits, header %d bits", ErrKeyDepth, LeafBits(k), 128<<leafLog)
+	}
+	return k, nil
+}
@@ -0,0 +1,70 @@
+package dpf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestParseKey(test *testing.T) {
+	for _, logN := range []uint64{0, 6, 7, 12} {
+		a, _ := Gen(0, logN)
+		if len(a) != KeyLen(logN) {
+			test.Fatalf("KeyLen(%d) = %d, key has %d bytes", logN, KeyLen(logN), len(a))
+		}
+		data := a.Encode(logN)
+		k, err := ParseKey(data, logN)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !bytes.Equal(k, a) {
+			test.Fatal("decoded key differs")
+		}
+		if _, err := ParseKey(data, logN+1); !errors.Is(err, ErrKeyDomain) {
+			test.Fatalf("expected domain error, got %v", err)
+		}
+		if _, err := ParseKey(data[:len(data)-1], logN); !errors.Is(err, ErrKeyLength) {
+			test.Fatalf("expected length error, got %v", err)
+		}
+		if _, err := ParseKey(data[:1], logN); !errors.Is(err, ErrKeyLength) {
+			test.Fatalf("expected length error, got %v", err)
+		}
+		data[0] = 0xff
+		if _, err := ParseKey(data, logN); !errors.Is(err, ErrKeyVersion) {
+			test.Fatalf("expected version error, got %v", err)
+		}
+	}
+}
+
+func TestParseKeyLeafBits(test *testing.T) {
+	a, _ := GenWithLeafBits(3, 12, 512, rand.Reader)
+	if len(a) != KeyLenWithLeafBits(12, 512) || LeafBits(a) != 512 {
+		test.Fatal("wrong key length")
+	}
+	data := a.Encode(12)
+	if data[0] != KeyVersionLeafBits || data[2] != 9 {
+		test.Fatal("wrong key header")
+	}
+	k, err := ParseKey(data, 12)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !bytes.Equal(k, a) {
+		test.Fatal("decoded key differs")
+	}
+	data[2] = 11
+	if _, err := ParseKey(data, 12); !errors.Is(err, ErrKeyDepth) {
+		test.Fatalf("expected depth error, got %v", err)
+	}
+	data[2] = 8
+	if _, err := ParseKey(data, 12); !errors.Is(err, ErrKeyLength) {
+		test.Fatalf("expected length error, got %v", err)
+	}
+	// the leaf width stored in the key has to match the header
+	data[2] = 9
+	data[3+16] &= 1
+	if _, err := ParseKey(data, 12); !errors.Is(err, ErrKeyDepth) {
+		test.Fatalf("expected depth error, got %v", err)
+	}
+}
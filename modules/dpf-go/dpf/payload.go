@@ -0,0 +1,227 @@
+package dpf
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Group of the DPF output, the two output shares combine to beta at alpha and to 0 elsewhere
+type Group byte
+
+const (
+	// outputs are byte strings, shares are combined with XOR
+	GroupXOR Group = iota
+	// outputs are vectors of little-endian uint64, shares are added mod 2^64
+	GroupAdd64
+)
+
+// header: group(1) | party(1) | logN(1) | payload length(4)
+const payloadHeaderLen = 7
+
+/*
+PayloadKey is a DPF key for the point function that is beta at alpha and 0 elsewhere,
+for a payload beta of arbitrary length.
+In contrast to DPFkey there is no early termination, the tree is expanded down to the leaves
+and each leaf seed is stretched to the payload length, so the key carries logN correction
+words and a final correction word of the payload length.
+*/
+type PayloadKey struct {
+	Group      Group
+	Party      byte // 0 or 1, the second party negates its output in GroupAdd64
+	LogN       uint64
+	PayloadLen int
+	tree       DPFkey // seed | t | logN * (sCW | tLCW | tRCW)
+	finalCW    []byte
+	ctx        *Context // nil for the default context
+}
+
+// GenPayload generates the two keys of a DPF with payload beta at alpha in a domain of size 2^logN.
+// For GroupAdd64 the length of beta has to be a multiple of 8.
+func GenPayload(alpha uint64, logN uint64, beta []byte, group Group) (*PayloadKey, *PayloadKey) {
+	return defaultContext.GenPayloadWithReader(alpha, logN, beta, group, rand.Reader)
+}
+
+// Like GenPayload, but the seeds are read from r
+func GenPayloadWithReader(alpha uint64, logN uint64, beta []byte, group Group, r io.Reader) (*PayloadKey, *PayloadKey) {
+	return defaultContext.GenPayloadWithReader(alpha, logN, beta, group, r)
+}
+
+func (c *Context) GenPayload(alpha uint64, logN uint64, beta []byte, group Group) (*PayloadKey, *PayloadKey) {
+	return c.GenPayloadWithReader(alpha, logN, beta, group, rand.Reader)
+}
+
+func (c *Context) GenPayloadWithReader(alpha uint64, logN uint64, beta []byte, group Group, r io.Reader) (*PayloadKey, *PayloadKey) {
+	if alpha >= (1<<logN) || logN > 63 || len(beta) == 0 || !validPayloadLen(group, len(beta)) {
+		panic("dpf: invalid parameters")
+	}
+	ka, kb, s0, s1, _, t1 := c.genTree(alpha, logN, logN, r)
+	c0 := c.convertPayload(s0, len(beta))
+	c1 := c.convertPayload(s1, len(beta))
+	cw := make([]byte, len(beta))
+	switch group {
+	case GroupXOR:
+		for i := range cw {
+			cw[i] = beta[i] ^ c0[i] ^ c1[i]
+		}
+	case GroupAdd64:
+		// the outputs of the two parties at alpha are c0 + t0*cw and -(c1 + t1*cw),
+		// with t0 != t1 the correction word is +-(beta - c0 + c1)
+		for i := 0; i < len(cw); i += 8 {
+			v := binary.LittleEndian.Uint64(beta[i:]) - binary.LittleEndian.Uint64(c0[i:]) + binary.LittleEndian.Uint64(c1[i:])
+			if t1 != 0 {
+				v = -v
+			}
+			binary.LittleEndian.PutUint64(cw[i:], v)
+		}
+	}
+	a := &PayloadKey{group, 0, logN, len(beta), ka, cw, c}
+	b := &PayloadKey{group, 1, logN, len(beta), kb, cw, c}
+	return a, b
+}
+
+func validPayloadLen(group Group, l int) bool {
+	switch group {
+	case GroupXOR:
+		return true
+	case GroupAdd64:
+		return l%8 == 0
+	}
+	return false
+}
+
+// stretches a leaf seed to l pseudorandom bytes, block j is AES-MMO of the seed with j XORed into it
+func (c *Context) convertPayload(s *block, l int) []byte {
+	out := make([]byte, (l+15)&^15)
+	in := new(block)
+	for j := 0; j < len(out)/16; j++ {
+		*in = *s
+		binary.LittleEndian.PutUint64(in[8:], binary.LittleEndian.Uint64(in[8:])^uint64(j))
+		aes128MMO(&c.keyL[0], &out[16*j], &in[0])
+	}
+	return out[:l]
+}
+
+func (k *PayloadKey) context() *Context {
+	if k.ctx == nil {
+		return defaultContext
+	}
+	return k.ctx
+}
+
+// Eval returns the share of the output at x
+func (k *PayloadKey) Eval(x uint64) []byte {
+	n := node{t: k.tree[16]}
+	copy(n.s[:], k.tree[:16])
+	for lvl := uint64(0); lvl < k.LogN; lvl++ {
+		l, r := k.context().expandNode(k.tree, &n, lvl)
+		if (x & (uint64(1) << (k.LogN - 1 - lvl))) != 0 {
+			n = r
+		} else {
+			n = l
+		}
+	}
+	return k.leaf(&n)
+}
+
+// EvalFull returns the shares of the outputs at all 2^LogN points, output x is at x*PayloadLen
+func (k *PayloadKey) EvalFull() []byte {
+	out := make([]byte, 0, k.PayloadLen<<k.LogN)
+	n := node{t: k.tree[16]}
+	copy(n.s[:], k.tree[:16])
+	var rec func(n *node, lvl uint64)
+	rec = func(n *node, lvl uint64) {
+		if lvl == k.LogN {
+			out = append(out, k.leaf(n)...)
+			return
+		}
+		l, r := k.context().expandNode(k.tree, n, lvl)
+		rec(&l, lvl+1)
+		rec(&r, lvl+1)
+	}
+	rec(&n, 0)
+	return out
+}
+
+func (k *PayloadKey) leaf(n *node) []byte {
+	out := k.context().convertPayload(&n.s, k.PayloadLen)
+	switch k.Group {
+	case GroupXOR:
+		if n.t != 0 {
+			for i := range out {
+				out[i] ^= k.finalCW[i]
+			}
+		}
+	case GroupAdd64:
+		for i := 0; i < len(out); i += 8 {
+			v := binary.LittleEndian.Uint64(out[i:])
+			if n.t != 0 {
+				v += binary.LittleEndian.Uint64(k.finalCW[i:])
+			}
+			if k.Party == 1 {
+				v = -v
+			}
+			binary.LittleEndian.PutUint64(out[i:], v)
+		}
+	}
+	return out
+}
+
+// Combine adds two output shares in the group of the keys
+func (g Group) Combine(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	switch g {
+	case GroupXOR:
+		for i := range out {
+			out[i] = a[i] ^ b[i]
+		}
+	case GroupAdd64:
+		for i := 0; i+8 <= len(out); i += 8 {
+			binary.LittleEndian.PutUint64(out[i:], binary.LittleEndian.Uint64(a[i:])+binary.LittleEndian.Uint64(b[i:]))
+		}
+	}
+	return out
+}
+
+// MarshalBinary encodes the key as
+// group(1) | party(1) | logN(1) | payload length(4, little-endian) | seed(16) | t(1) | logN * (sCW(16) | tLCW(1) | tRCW(1)) | final CW
+func (k *PayloadKey) MarshalBinary() ([]byte, error) {
+	out := make([]byte, payloadHeaderLen, payloadHeaderLen+len(k.tree)+len(k.finalCW))
+	out[0] = byte(k.Group)
+	out[1] = k.Party
+	out[2] = byte(k.LogN)
+	binary.LittleEndian.PutUint32(out[3:], uint32(k.PayloadLen))
+	out = append(out, k.tree...)
+	out = append(out, k.finalCW...)
+	return out, nil
+}
+
+// ParsePayloadKey decodes a key encoded with MarshalBinary that is evaluated with c
+func (c *Context) ParsePayloadKey(data []byte) (*PayloadKey, error) {
+	k := &PayloadKey{ctx: c}
+	if err := k.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// UnmarshalBinary decodes a key encoded with MarshalBinary, the context of the key is not changed
+func (k *PayloadKey) UnmarshalBinary(data []byte) error {
+	if len(data) < payloadHeaderLen {
+		return errors.New("dpf: payload key too short")
+	}
+	group, party, logN := Group(data[0]), data[1], uint64(data[2])
+	payloadLen := int(binary.LittleEndian.Uint32(data[3:]))
+	if party > 1 || logN > 63 || payloadLen == 0 || !validPayloadLen(group, payloadLen) {
+		return errors.New("dpf: invalid payload key header")
+	}
+	treeLen := 17 + 18*int(logN)
+	if len(data) != payloadHeaderLen+treeLen+payloadLen {
+		return errors.New("dpf: payload key has wrong length")
+	}
+	k.Group, k.Party, k.LogN, k.PayloadLen = group, party, logN, payloadLen
+	k.tree = append(DPFkey(nil), data[payloadHeaderLen:payloadHeaderLen+treeLen]...)
+	k.finalCW = append([]byte(nil), data[payloadHeaderLen+treeLen:]...)
+	return nil
+}
@@ -0,0 +1,53 @@
+package dpf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPayload(test *testing.T) {
+	for _, group := range []Group{GroupXOR, GroupAdd64} {
+		for _, logN := range []uint64{0, 1, 5, 9} {
+			beta := make([]byte, 40)
+			for i := range beta {
+				beta[i] = byte(3*i + 1)
+			}
+			alpha := uint64(1)<<logN - 1
+			a, b := GenPayload(alpha, logN, beta, group)
+			fullA, fullB := a.EvalFull(), b.EvalFull()
+			zero := make([]byte, len(beta))
+			for x := uint64(0); x < 1<<logN; x++ {
+				shareA, shareB := a.Eval(x), b.Eval(x)
+				if !bytes.Equal(shareA, fullA[x*40:(x+1)*40]) || !bytes.Equal(shareB, fullB[x*40:(x+1)*40]) {
+					test.Fatalf("Eval and EvalFull differ at %d (group %d, logN %d)", x, group, logN)
+				}
+				expected := zero
+				if x == alpha {
+					expected = beta
+				}
+				if !bytes.Equal(group.Combine(shareA, shareB), expected) {
+					test.Fatalf("wrong output at %d (group %d, logN %d)", x, group, logN)
+				}
+			}
+		}
+	}
+}
+
+func TestPayloadSerialization(test *testing.T) {
+	a, _ := GenPayload(3, 6, []byte("a payload that is not a multiple of 16"), GroupXOR)
+	data, _ := a.MarshalBinary()
+	decoded := new(PayloadKey)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		test.Fatal(err)
+	}
+	if !bytes.Equal(decoded.EvalFull(), a.EvalFull()) {
+		test.Fatal("decoded key evaluates differently")
+	}
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		test.Fatal("truncated key was accepted")
+	}
+	data[0] = byte(GroupAdd64)
+	if err := decoded.UnmarshalBinary(data); err == nil {
+		test.Fatal("payload length not a multiple of 8 was accepted for GroupAdd64")
+	}
+}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"crypto/rand"
 	"flag"
 	"log"
 	"os"
 	"runtime/pprof"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dkales/dpf-go/dpf"
 )
 
 var cpuprofile = flag.String("cpuprofile", "", "write cpuprofile to file")
+var logN = flag.Uint64("logN", 27, "domain size 2^logN")
+var iterations = flag.Int("iterations", 100, "number of EvalFull calls per leaf width")
+var leafBits = flag.String("leafBits", "128", "comma separated leaf widths to compare, e.g. 128,256,512")
 
 func main() {
 	flag.Parse()
@@ -22,10 +28,16 @@ func main() {
 		pprof.StartCPUProfile(f)
 		defer pprof.StopCPUProfile()
 	}
-	a, _ := dpf.Gen(123, 27)
-	evalStart := time.Now()
-	for i := 0; i < 100; i++ {
-		dpf.EvalFull(a, 27)
+	for _, w := range strings.Split(*leafBits, ",") {
+		bits, err := strconv.Atoi(w)
+		if err != nil {
+			log.Fatal(err)
+		}
+		a, _ := dpf.GenWithLeafBits(123, *logN, bits, rand.Reader)
+		evalStart := time.Now()
+		for i := 0; i < *iterations; i++ {
+			dpf.EvalFull(a, *logN)
+		}
+		log.Println("leaf bits", bits, "key size", len(a), "EvalFull time", time.Since(evalStart))
 	}
-	log.Println("EvalFull time", time.Since(evalStart))
 }
@@ -25,14 +25,21 @@ type Config struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ResetServer bool   `protobuf:"varint,1,opt,name=resetServer,proto3" json:"resetServer,omitempty"`
-	Dbfile      string `protobuf:"bytes,2,opt,name=dbfile,proto3" json:"dbfile,omitempty"`
-	MultiClient bool   `protobuf:"varint,3,opt,name=multiClient,proto3" json:"multiClient,omitempty"` //flag for benchmarking multi-client setting
-	NumThreads  uint32 `protobuf:"varint,4,opt,name=numThreads,proto3" json:"numThreads,omitempty"`   //number of server threads
-	CIdx        uint32 `protobuf:"varint,5,opt,name=cIdx,proto3" json:"cIdx,omitempty"`               //client sends index to obtain its KW
-	NumTargets  uint32 `protobuf:"varint,6,opt,name=numTargets,proto3" json:"numTargets,omitempty"`   // how many receiver sender wants to contact
-	ServerID    uint32 `protobuf:"varint,7,opt,name=serverID,proto3" json:"serverID,omitempty"`       //0 or 1 indicating which server is used
-	DbType      []byte `protobuf:"bytes,8,opt,name=dbType,proto3" json:"dbType,omitempty"`
+	ResetServer    bool   `protobuf:"varint,1,opt,name=resetServer,proto3" json:"resetServer,omitempty"`
+	Dbfile         string `protobuf:"bytes,2,opt,name=dbfile,proto3" json:"dbfile,omitempty"`
+	MultiClient    bool   `protobuf:"varint,3,opt,name=multiClient,proto3" json:"multiClient,omitempty"` //flag for benchmarking multi-client setting
+	NumThreads     uint32 `protobuf:"varint,4,opt,name=numThreads,proto3" json:"numThreads,omitempty"`   //number of server threads
+	CIdx           uint32 `protobuf:"varint,5,opt,name=cIdx,proto3" json:"cIdx,omitempty"`               //client sends index to obtain its KW
+	NumTargets     uint32 `protobuf:"varint,6,opt,name=numTargets,proto3" json:"numTargets,omitempty"`   // how many receiver sender wants to contact
+	ServerID       uint32 `protobuf:"varint,7,opt,name=serverID,proto3" json:"serverID,omitempty"`       //0 or 1 indicating which server is used
+	DbType         []byte `protobuf:"bytes,8,opt,name=dbType,proto3" json:"dbType,omitempty"`
+	SingleServer   bool   `protobuf:"varint,9,opt,name=singleServer,proto3" json:"singleServer,omitempty"`      //use single-server LWE PIR for retrieval
+	PayloadLen     uint32 `protobuf:"varint,10,opt,name=payloadLen,proto3" json:"payloadLen,omitempty"`         //bytes of secret-shared payload per notification, 0 disables payloads
+	EpochLength    uint64 `protobuf:"varint,11,opt,name=epochLength,proto3" json:"epochLength,omitempty"`       //length of a notification epoch (round) in seconds, 0 disables epochs
+	EpochStart     int64  `protobuf:"varint,12,opt,name=epochStart,proto3" json:"epochStart,omitempty"`         //unix time of the begin of epoch 0
+	EpochRetention uint32 `protobuf:"varint,13,opt,name=epochRetention,proto3" json:"epochRetention,omitempty"` //number of readable epochs the servers keep
+	VerifyColumns  bool   `protobuf:"varint,14,opt,name=verifyColumns,proto3" json:"verifyColumns,omitempty"`   //servers check that written columns have at most numTargets ones
+	Reshare        bool   `protobuf:"varint,15,opt,name=reshare,proto3" json:"reshare,omitempty"`               //servers re-randomize the stored shares whenever an epoch is published
 }
 
 func (x *Config) Reset() {
@@ -123,6 +130,55 @@ func (x *Config) GetDbType() []byte {
 	return nil
 }
 
+func (x *Config) GetSingleServer() bool {
+	if x != nil {
+		return x.SingleServer
+	}
+	return false
+}
+
+func (x *Config) GetPayloadLen() uint32 {
+	if x != nil {
+		return x.PayloadLen
+	}
+	return 0
+}
+
+func (x *Config) GetEpochLength() uint64 {
+	if x != nil {
+		return x.EpochLength
+	}
+	return 0
+}
+
+func (x *Config) GetEpochStart() int64 {
+	if x != nil {
+		return x.EpochStart
+	}
+	return 0
+}
+
+func (x *Config) GetEpochRetention() uint32 {
+	if x != nil {
+		return x.EpochRetention
+	}
+	return 0
+}
+
+func (x *Config) GetVerifyColumns() bool {
+	if x != nil {
+		return x.VerifyColumns
+	}
+	return false
+}
+
+func (x *Config) GetReshare() bool {
+	if x != nil {
+		return x.Reshare
+	}
+	return false
+}
+
 // basically nothing needs to be transmitted here, just a "give params" request
 type ParamRequest struct {
 	state         protoimpl.MessageState
@@ -453,7 +509,8 @@ type Query struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DpfKey []byte `protobuf:"bytes,1,opt,name=dpfKey,proto3" json:"dpfKey,omitempty"`
+	DpfKey   []byte `protobuf:"bytes,1,opt,name=dpfKey,proto3" json:"dpfKey,omitempty"`
+	LweQuery []byte `protobuf:"bytes,2,opt,name=lweQuery,proto3" json:"lweQuery,omitempty"` //flat list of uint32, little-endian
 }
 
 func (x *Query) Reset() {
@@ -495,6 +552,13 @@ func (x *Query) GetDpfKey() []byte {
 	return nil
 }
 
+func (x *Query) GetLweQuery() []byte {
+	if x != nil {
+		return x.LweQuery
+	}
+	return nil
+}
+
 type Queries struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -547,7 +611,7 @@ type Answer struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Answer []byte `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"`
+	Answer []byte `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"` //flat list of uint32, little-endian, for LWE answers
 }
 
 func (x *Answer) Reset() {
@@ -641,8 +705,16 @@ type NotifyRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Idx uint32  `protobuf:"varint,1,opt,name=idx,proto3" json:"idx,omitempty"`
-	Vec *Vector `protobuf:"bytes,2,opt,name=vec,proto3" json:"vec,omitempty"`
+	Idx         uint32   `protobuf:"varint,1,opt,name=idx,proto3" json:"idx,omitempty"`
+	Vec         *Vector  `protobuf:"bytes,2,opt,name=vec,proto3" json:"vec,omitempty"`
+	Epoch       uint64   `protobuf:"varint,3,opt,name=epoch,proto3" json:"epoch,omitempty"`            //epoch the column is written to, has to be the current epoch of the server
+	Parts       [][]byte `protobuf:"bytes,4,rep,name=parts,proto3" json:"parts,omitempty"`             //shares of the column split in parts with at most one target each, instead of vec.val (verifyColumns)
+	Triples     []byte   `protobuf:"bytes,5,opt,name=triples,proto3" json:"triples,omitempty"`         //shares of one multiplication triple per part for the verification
+	WriteId     []byte   `protobuf:"bytes,6,opt,name=writeId,proto3" json:"writeId,omitempty"`         //random id the servers use to match their verifications of the write
+	Keys        [][]byte `protobuf:"bytes,7,rep,name=keys,proto3" json:"keys,omitempty"`               //DPF keys of the targets and dummies, expanded to the parts by the server (DPF notifications)
+	PayloadKeys [][]byte `protobuf:"bytes,8,rep,name=payloadKeys,proto3" json:"payloadKeys,omitempty"` //payload DPF keys of the targets and dummies, instead of vec.payload
+	MacSeed     []byte   `protobuf:"bytes,9,opt,name=macSeed,proto3" json:"macSeed,omitempty"`         //seed of the share of the MAC keys of the column, if the database is authenticated
+	MacKeys     [][]byte `protobuf:"bytes,10,rep,name=macKeys,proto3" json:"macKeys,omitempty"`        //payload DPF keys of the MAC tags of the targets and dummies, instead of vec.mac
 }
 
 func (x *NotifyRequest) Reset() {
@@ -691,6 +763,62 @@ func (x *NotifyRequest) GetVec() *Vector {
 	return nil
 }
 
+func (x *NotifyRequest) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *NotifyRequest) GetParts() [][]byte {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetTriples() []byte {
+	if x != nil {
+		return x.Triples
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetWriteId() []byte {
+	if x != nil {
+		return x.WriteId
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetKeys() [][]byte {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetPayloadKeys() [][]byte {
+	if x != nil {
+		return x.PayloadKeys
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetMacSeed() []byte {
+	if x != nil {
+		return x.MacSeed
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetMacKeys() [][]byte {
+	if x != nil {
+		return x.MacKeys
+	}
+	return nil
+}
+
 type Index struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -738,16 +866,18 @@ func (x *Index) GetIdx() uint32 {
 	return 0
 }
 
-type Vector struct {
+type EpochIndex struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Val []byte `protobuf:"bytes,1,opt,name=val,proto3" json:"val,omitempty"`
+	Idx  uint32 `protobuf:"varint,1,opt,name=idx,proto3" json:"idx,omitempty"`
+	From uint64 `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"` //first epoch
+	To   uint64 `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`     //last epoch (inclusive), epochs that are not readable yet are ignored
 }
 
-func (x *Vector) Reset() {
-	*x = Vector{}
+func (x *EpochIndex) Reset() {
+	*x = EpochIndex{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_bootstrapping_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -755,13 +885,13 @@ func (x *Vector) Reset() {
 	}
 }
 
-func (x *Vector) String() string {
+func (x *EpochIndex) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Vector) ProtoMessage() {}
+func (*EpochIndex) ProtoMessage() {}
 
-func (x *Vector) ProtoReflect() protoreflect.Message {
+func (x *EpochIndex) ProtoReflect() protoreflect.Message {
 	mi := &file_bootstrapping_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -773,28 +903,45 @@ func (x *Vector) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Vector.ProtoReflect.Descriptor instead.
-func (*Vector) Descriptor() ([]byte, []int) {
+// Deprecated: Use EpochIndex.ProtoReflect.Descriptor instead.
+func (*EpochIndex) Descriptor() ([]byte, []int) {
 	return file_bootstrapping_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *Vector) GetVal() []byte {
+func (x *EpochIndex) GetIdx() uint32 {
 	if x != nil {
-		return x.Val
+		return x.Idx
 	}
-	return nil
+	return 0
 }
 
-type Ack struct {
+func (x *EpochIndex) GetFrom() uint64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *EpochIndex) GetTo() uint64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+type EpochRows struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Rows      []*Vector `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`            //one row per epoch, starting at epoch from
+	From      uint64    `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`           //epoch of the first row, later than requested if older epochs have expired
+	Current   uint64    `protobuf:"varint,3,opt,name=current,proto3" json:"current,omitempty"`     //epoch the server currently writes to
+	Published uint64    `protobuf:"varint,4,opt,name=published,proto3" json:"published,omitempty"` //epochs before are published, every publication reshares the rows if resharing is enabled
 }
 
-func (x *Ack) Reset() {
-	*x = Ack{}
+func (x *EpochRows) Reset() {
+	*x = EpochRows{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_bootstrapping_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -802,13 +949,13 @@ func (x *Ack) Reset() {
 	}
 }
 
-func (x *Ack) String() string {
+func (x *EpochRows) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Ack) ProtoMessage() {}
+func (*EpochRows) ProtoMessage() {}
 
-func (x *Ack) ProtoReflect() protoreflect.Message {
+func (x *EpochRows) ProtoReflect() protoreflect.Message {
 	mi := &file_bootstrapping_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -820,212 +967,1192 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
-func (*Ack) Descriptor() ([]byte, []int) {
+// Deprecated: Use EpochRows.ProtoReflect.Descriptor instead.
+func (*EpochRows) Descriptor() ([]byte, []int) {
 	return file_bootstrapping_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *Ack) GetOk() bool {
+func (x *EpochRows) GetRows() []*Vector {
 	if x != nil {
-		return x.Ok
+		return x.Rows
 	}
-	return false
+	return nil
 }
 
-var File_bootstrapping_proto protoreflect.FileDescriptor
+func (x *EpochRows) GetFrom() uint64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
 
-var file_bootstrapping_proto_rawDesc = []byte{
-	0x0a, 0x13, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70,
-	0x70, 0x69, 0x6e, 0x67, 0x22, 0xec, 0x01, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
-	0x20, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65,
-	0x72, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x62, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x64, 0x62, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x75, 0x6c,
-	0x74, 0x69, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b,
-	0x6d, 0x75, 0x6c, 0x74, 0x69, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x6e,
-	0x75, 0x6d, 0x54, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x63,
-	0x49, 0x64, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x49, 0x64, 0x78, 0x12,
-	0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x12,
-	0x1a, 0x0a, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x64,
-	0x62, 0x54, 0x79, 0x70, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x62, 0x54,
-	0x79, 0x70, 0x65, 0x22, 0x42, 0x0a, 0x0c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x49, 0x64, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x04, 0x63, 0x49, 0x64, 0x78, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x61,
-	0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6e, 0x75, 0x6d,
-	0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x22, 0x66, 0x0a, 0x09, 0x50, 0x61, 0x72, 0x61, 0x6d,
-	0x52, 0x65, 0x73, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x4b, 0x57, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x03, 0x63, 0x4b, 0x57, 0x12, 0x2d, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
-	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72,
-	0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x06, 0x70,
-	0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x22,
-	0xce, 0x02, 0x0a, 0x06, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x72,
-	0x6f, 0x77, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6e, 0x72, 0x6f, 0x77, 0x73,
-	0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04,
-	0x61, 0x75, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x04, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x67, 0x4c,
-	0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x73, 0x65, 0x67, 0x4c, 0x65, 0x6e,
-	0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x65, 0x67, 0x4c, 0x65, 0x6e, 0x4d, 0x61, 0x73, 0x6b, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x73, 0x65, 0x67, 0x4c, 0x65, 0x6e, 0x4d, 0x61, 0x73, 0x6b,
-	0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x08, 0x73, 0x65, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b,
-	0x73, 0x65, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x0b, 0x73, 0x65, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x65, 0x6e, 0x12, 0x16,
-	0x0a, 0x06, 0x6b, 0x65, 0x79, 0x4c, 0x65, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06,
-	0x6b, 0x65, 0x79, 0x4c, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x4c, 0x65, 0x6e,
-	0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x4c, 0x65, 0x6e, 0x12, 0x1c,
-	0x0a, 0x09, 0x72, 0x65, 0x63, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x0a, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x09, 0x72, 0x65, 0x63, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04,
-	0x72, 0x6f, 0x6f, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74,
-	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x4c, 0x65, 0x6e, 0x18, 0x0c, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x4c, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04,
-	0x6c, 0x69, 0x73, 0x74, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x04, 0x6c, 0x69, 0x73, 0x74,
-	0x22, 0x6c, 0x0a, 0x05, 0x53, 0x65, 0x74, 0x75, 0x70, 0x12, 0x33, 0x0a, 0x06, 0x70, 0x61, 0x72,
-	0x61, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x62, 0x6f, 0x6f, 0x74,
-	0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x14,
-	0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x4b, 0x57, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6f,
-	0x77, 0x6e, 0x4b, 0x57, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x22, 0x1f,
-	0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x70, 0x66, 0x4b, 0x65,
-	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x70, 0x66, 0x4b, 0x65, 0x79, 0x22,
-	0x39, 0x0a, 0x07, 0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x07, 0x71, 0x75,
-	0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x6f,
-	0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x51, 0x75, 0x65, 0x72,
-	0x79, 0x52, 0x07, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x22, 0x20, 0x0a, 0x06, 0x41, 0x6e,
-	0x73, 0x77, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x22, 0x3a, 0x0a, 0x07,
-	0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x12, 0x2f, 0x0a, 0x07, 0x61, 0x6e, 0x73, 0x77, 0x65,
-	0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73,
-	0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x52,
-	0x07, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x22, 0x4a, 0x0a, 0x0d, 0x4e, 0x6f, 0x74, 0x69,
-	0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x78,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x69, 0x64, 0x78, 0x12, 0x27, 0x0a, 0x03, 0x76,
-	0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73,
-	0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52,
-	0x03, 0x76, 0x65, 0x63, 0x22, 0x19, 0x0a, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x10, 0x0a,
-	0x03, 0x69, 0x64, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x69, 0x64, 0x78, 0x22,
-	0x1a, 0x0a, 0x06, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x22, 0x15, 0x0a, 0x03, 0x41,
-	0x63, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02,
-	0x6f, 0x6b, 0x32, 0x9b, 0x03, 0x0a, 0x0d, 0x42, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70,
-	0x70, 0x69, 0x6e, 0x67, 0x12, 0x44, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x75, 0x70, 0x45, 0x78, 0x70,
-	0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74,
-	0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x1a, 0x18,
-	0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50,
-	0x61, 0x72, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0d, 0x47, 0x65,
-	0x74, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x1b, 0x2e, 0x62, 0x6f,
-	0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50, 0x61, 0x72, 0x61,
-	0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73,
-	0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22,
-	0x00, 0x12, 0x3f, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1c,
-	0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x4e,
-	0x6f, 0x74, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x62,
-	0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x63, 0x6b,
-	0x22, 0x00, 0x12, 0x37, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x77, 0x12, 0x14, 0x2e, 0x62,
-	0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x49, 0x6e, 0x64,
-	0x65, 0x78, 0x1a, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69,
-	0x6e, 0x67, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x0c, 0x4d,
-	0x61, 0x6b, 0x65, 0x49, 0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x62, 0x6f,
-	0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x51, 0x75, 0x65, 0x72,
-	0x69, 0x65, 0x73, 0x1a, 0x16, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70,
-	0x69, 0x6e, 0x67, 0x2e, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x22, 0x00, 0x12, 0x41, 0x0a,
-	0x0d, 0x4d, 0x61, 0x6b, 0x65, 0x4b, 0x57, 0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x16,
-	0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x51,
-	0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x1a, 0x16, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72,
-	0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x22, 0x00,
-	0x42, 0x15, 0x5a, 0x13, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74,
-	0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *EpochRows) GetCurrent() uint64 {
+	if x != nil {
+		return x.Current
+	}
+	return 0
 }
 
-var (
-	file_bootstrapping_proto_rawDescOnce sync.Once
-	file_bootstrapping_proto_rawDescData = file_bootstrapping_proto_rawDesc
-)
+func (x *EpochRows) GetPublished() uint64 {
+	if x != nil {
+		return x.Published
+	}
+	return 0
+}
 
-func file_bootstrapping_proto_rawDescGZIP() []byte {
-	file_bootstrapping_proto_rawDescOnce.Do(func() {
-		file_bootstrapping_proto_rawDescData = protoimpl.X.CompressGZIP(file_bootstrapping_proto_rawDescData)
-	})
-	return file_bootstrapping_proto_rawDescData
+type RoundRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Time int64  `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"` //unix time the coordinator sent the request at
+	Mac  []byte `protobuf:"bytes,2,opt,name=mac,proto3" json:"mac,omitempty"`    //authenticates the request with the round key
 }
 
-var file_bootstrapping_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
-var file_bootstrapping_proto_goTypes = []interface{}{
-	(*Config)(nil),        // 0: bootstrapping.Config
-	(*ParamRequest)(nil),  // 1: bootstrapping.ParamRequest
-	(*ParamResp)(nil),     // 2: bootstrapping.ParamResp
-	(*Params)(nil),        // 3: bootstrapping.Params
-	(*Setup)(nil),         // 4: bootstrapping.Setup
-	(*Query)(nil),         // 5: bootstrapping.Query
-	(*Queries)(nil),       // 6: bootstrapping.Queries
-	(*Answer)(nil),        // 7: bootstrapping.Answer
-	(*Answers)(nil),       // 8: bootstrapping.Answers
-	(*NotifyRequest)(nil), // 9: bootstrapping.NotifyRequest
-	(*Index)(nil),         // 10: bootstrapping.Index
-	(*Vector)(nil),        // 11: bootstrapping.Vector
-	(*Ack)(nil),           // 12: bootstrapping.Ack
+func (x *RoundRequest) Reset() {
+	*x = RoundRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
-var file_bootstrapping_proto_depIdxs = []int32{
-	3,  // 0: bootstrapping.ParamResp.params:type_name -> bootstrapping.Params
-	1,  // 1: bootstrapping.Setup.params:type_name -> bootstrapping.ParamRequest
-	5,  // 2: bootstrapping.Queries.queries:type_name -> bootstrapping.Query
-	7,  // 3: bootstrapping.Answers.answers:type_name -> bootstrapping.Answer
-	11, // 4: bootstrapping.NotifyRequest.vec:type_name -> bootstrapping.Vector
-	0,  // 5: bootstrapping.Bootstrapping.SetupExperiment:input_type -> bootstrapping.Config
-	1,  // 6: bootstrapping.Bootstrapping.GetParameters:input_type -> bootstrapping.ParamRequest
-	9,  // 7: bootstrapping.Bootstrapping.SetColumn:input_type -> bootstrapping.NotifyRequest
-	10, // 8: bootstrapping.Bootstrapping.GetRow:input_type -> bootstrapping.Index
-	6,  // 9: bootstrapping.Bootstrapping.MakeIQueries:input_type -> bootstrapping.Queries
-	6,  // 10: bootstrapping.Bootstrapping.MakeKWQueries:input_type -> bootstrapping.Queries
-	2,  // 11: bootstrapping.Bootstrapping.SetupExperiment:output_type -> bootstrapping.ParamResp
-	3,  // 12: bootstrapping.Bootstrapping.GetParameters:output_type -> bootstrapping.Params
-	12, // 13: bootstrapping.Bootstrapping.SetColumn:output_type -> bootstrapping.Ack
-	11, // 14: bootstrapping.Bootstrapping.GetRow:output_type -> bootstrapping.Vector
-	8,  // 15: bootstrapping.Bootstrapping.MakeIQueries:output_type -> bootstrapping.Answers
-	8,  // 16: bootstrapping.Bootstrapping.MakeKWQueries:output_type -> bootstrapping.Answers
-	11, // [11:17] is the sub-list for method output_type
-	5,  // [5:11] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+
+func (x *RoundRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_bootstrapping_proto_init() }
-func file_bootstrapping_proto_init() {
-	if File_bootstrapping_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_bootstrapping_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Config); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+func (*RoundRequest) ProtoMessage() {}
+
+func (x *RoundRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_bootstrapping_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ParamRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundRequest.ProtoReflect.Descriptor instead.
+func (*RoundRequest) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RoundRequest) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *RoundRequest) GetMac() []byte {
+	if x != nil {
+		return x.Mac
+	}
+	return nil
+}
+
+type RoundWrites struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Epoch      uint64   `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Columns    []uint32 `protobuf:"varint,2,rep,packed,name=columns,proto3" json:"columns,omitempty"` //columns written in the epoch
+	Counts     []uint32 `protobuf:"varint,3,rep,packed,name=counts,proto3" json:"counts,omitempty"`   //number of writes to each column
+	ReshareTag []byte   `protobuf:"bytes,4,opt,name=reshareTag,proto3" json:"reshareTag,omitempty"`   //tag of the resharing key of the server (CloseRound), empty if it does not reshare
+	Time       int64    `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`              //unix time the coordinator sent the publication at (PublishRound)
+	Mac        []byte   `protobuf:"bytes,7,opt,name=mac,proto3" json:"mac,omitempty"`                 //authenticates the publication with the round key (PublishRound)
+}
+
+func (x *RoundWrites) Reset() {
+	*x = RoundWrites{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoundWrites) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundWrites) ProtoMessage() {}
+
+func (x *RoundWrites) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_bootstrapping_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundWrites.ProtoReflect.Descriptor instead.
+func (*RoundWrites) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RoundWrites) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *RoundWrites) GetColumns() []uint32 {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *RoundWrites) GetCounts() []uint32 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+func (x *RoundWrites) GetReshareTag() []byte {
+	if x != nil {
+		return x.ReshareTag
+	}
+	return nil
+}
+
+func (x *RoundWrites) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *RoundWrites) GetMac() []byte {
+	if x != nil {
+		return x.Mac
+	}
+	return nil
+}
+
+type DurableRound struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Published  uint64       `protobuf:"varint,1,opt,name=published,proto3" json:"published,omitempty"`   //epochs before are published, and on disk if the server keeps a log
+	Current    uint64       `protobuf:"varint,2,opt,name=current,proto3" json:"current,omitempty"`       //epoch the server currently writes to
+	Last       *RoundWrites `protobuf:"bytes,3,opt,name=last,proto3" json:"last,omitempty"`              //writes of the last published epoch, for completing an interrupted flip
+	Persistent bool         `protobuf:"varint,4,opt,name=persistent,proto3" json:"persistent,omitempty"` //server keeps a log of the notifications
+}
+
+func (x *DurableRound) Reset() {
+	*x = DurableRound{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DurableRound) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DurableRound) ProtoMessage() {}
+
+func (x *DurableRound) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DurableRound.ProtoReflect.Descriptor instead.
+func (*DurableRound) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DurableRound) GetPublished() uint64 {
+	if x != nil {
+		return x.Published
+	}
+	return 0
+}
+
+func (x *DurableRound) GetCurrent() uint64 {
+	if x != nil {
+		return x.Current
+	}
+	return 0
+}
+
+func (x *DurableRound) GetLast() *RoundWrites {
+	if x != nil {
+		return x.Last
+	}
+	return nil
+}
+
+func (x *DurableRound) GetPersistent() bool {
+	if x != nil {
+		return x.Persistent
+	}
+	return false
+}
+
+type SketchMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	WriteId  []byte   `protobuf:"bytes,1,opt,name=writeId,proto3" json:"writeId,omitempty"`
+	Round    uint32   `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	ServerID uint32   `protobuf:"varint,3,opt,name=serverID,proto3" json:"serverID,omitempty"` //sender of the message
+	Values   []uint64 `protobuf:"varint,4,rep,packed,name=values,proto3" json:"values,omitempty"`
+	Mac      []byte   `protobuf:"bytes,5,opt,name=mac,proto3" json:"mac,omitempty"`      //authenticates the message with the key shared by the servers
+	Idx      uint32   `protobuf:"varint,6,opt,name=idx,proto3" json:"idx,omitempty"`     //column of the verified write
+	Epoch    uint64   `protobuf:"varint,7,opt,name=epoch,proto3" json:"epoch,omitempty"` //epoch of the verified write
+}
+
+func (x *SketchMessage) Reset() {
+	*x = SketchMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SketchMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SketchMessage) ProtoMessage() {}
+
+func (x *SketchMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SketchMessage.ProtoReflect.Descriptor instead.
+func (*SketchMessage) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SketchMessage) GetWriteId() []byte {
+	if x != nil {
+		return x.WriteId
+	}
+	return nil
+}
+
+func (x *SketchMessage) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SketchMessage) GetServerID() uint32 {
+	if x != nil {
+		return x.ServerID
+	}
+	return 0
+}
+
+func (x *SketchMessage) GetValues() []uint64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *SketchMessage) GetMac() []byte {
+	if x != nil {
+		return x.Mac
+	}
+	return nil
+}
+
+func (x *SketchMessage) GetIdx() uint32 {
+	if x != nil {
+		return x.Idx
+	}
+	return 0
+}
+
+func (x *SketchMessage) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+type ReshareNonceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Epoch    uint64 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	ServerID uint32 `protobuf:"varint,2,opt,name=serverID,proto3" json:"serverID,omitempty"` //sender of the request
+	Mac      []byte `protobuf:"bytes,3,opt,name=mac,proto3" json:"mac,omitempty"`            //authenticates the request with the key shared by the servers
+}
+
+func (x *ReshareNonceRequest) Reset() {
+	*x = ReshareNonceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReshareNonceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReshareNonceRequest) ProtoMessage() {}
+
+func (x *ReshareNonceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReshareNonceRequest.ProtoReflect.Descriptor instead.
+func (*ReshareNonceRequest) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReshareNonceRequest) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *ReshareNonceRequest) GetServerID() uint32 {
+	if x != nil {
+		return x.ServerID
+	}
+	return 0
+}
+
+func (x *ReshareNonceRequest) GetMac() []byte {
+	if x != nil {
+		return x.Mac
+	}
+	return nil
+}
+
+type ReshareNonce struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Epoch    uint64 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	ServerID uint32 `protobuf:"varint,2,opt,name=serverID,proto3" json:"serverID,omitempty"` //sender of the randomness
+	Nonce    []byte `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`        //randomness the server contributes to the resharing of the epoch
+	Mac      []byte `protobuf:"bytes,4,opt,name=mac,proto3" json:"mac,omitempty"`            //authenticates the randomness with the key shared by the servers
+}
+
+func (x *ReshareNonce) Reset() {
+	*x = ReshareNonce{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReshareNonce) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReshareNonce) ProtoMessage() {}
+
+func (x *ReshareNonce) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReshareNonce.ProtoReflect.Descriptor instead.
+func (*ReshareNonce) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReshareNonce) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *ReshareNonce) GetServerID() uint32 {
+	if x != nil {
+		return x.ServerID
+	}
+	return 0
+}
+
+func (x *ReshareNonce) GetNonce() []byte {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *ReshareNonce) GetMac() []byte {
+	if x != nil {
+		return x.Mac
+	}
+	return nil
+}
+
+type Vector struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Val     []byte `protobuf:"bytes,1,opt,name=val,proto3" json:"val,omitempty"`
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"` //share of the payload column (SetColumn) or row (GetRow), if payloads are enabled
+	Epoch   uint64 `protobuf:"varint,3,opt,name=epoch,proto3" json:"epoch,omitempty"`    //epoch of the row (GetRow)
+	Mac     []byte `protobuf:"bytes,4,opt,name=mac,proto3" json:"mac,omitempty"`         //share of the MAC tags of the column (SetColumn) or of the MAC keys and tags of the row (GetRow), if the database is authenticated
+}
+
+func (x *Vector) Reset() {
+	*x = Vector{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Vector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vector) ProtoMessage() {}
+
+func (x *Vector) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vector.ProtoReflect.Descriptor instead.
+func (*Vector) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Vector) GetVal() []byte {
+	if x != nil {
+		return x.Val
+	}
+	return nil
+}
+
+func (x *Vector) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Vector) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *Vector) GetMac() []byte {
+	if x != nil {
+		return x.Mac
+	}
+	return nil
+}
+
+type Ack struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ok    bool   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Epoch uint64 `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"` //epoch the server currently writes to
+}
+
+func (x *Ack) Reset() {
+	*x = Ack{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ack) ProtoMessage() {}
+
+func (x *Ack) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
+func (*Ack) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Ack) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *Ack) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+type HintRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueryType []byte `protobuf:"bytes,1,opt,name=queryType,proto3" json:"queryType,omitempty"` //database the hint is requested for
+	From      uint32 `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`          //first hint row
+	To        uint32 `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`              //last hint row (exclusive)
+}
+
+func (x *HintRequest) Reset() {
+	*x = HintRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HintRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HintRequest) ProtoMessage() {}
+
+func (x *HintRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HintRequest.ProtoReflect.Descriptor instead.
+func (*HintRequest) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *HintRequest) GetQueryType() []byte {
+	if x != nil {
+		return x.QueryType
+	}
+	return nil
+}
+
+func (x *HintRequest) GetFrom() uint32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *HintRequest) GetTo() uint32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+type LWEParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	N          uint32 `protobuf:"varint,1,opt,name=n,proto3" json:"n,omitempty"` //LWE secret dimension
+	NumRecords uint32 `protobuf:"varint,2,opt,name=numRecords,proto3" json:"numRecords,omitempty"`
+	RowLen     uint32 `protobuf:"varint,3,opt,name=rowLen,proto3" json:"rowLen,omitempty"`
+	NumCols    uint32 `protobuf:"varint,4,opt,name=numCols,proto3" json:"numCols,omitempty"`
+	M          uint32 `protobuf:"varint,5,opt,name=m,proto3" json:"m,omitempty"`
+	Seed       []byte `protobuf:"bytes,6,opt,name=seed,proto3" json:"seed,omitempty"` //seed of the public matrix
+}
+
+func (x *LWEParams) Reset() {
+	*x = LWEParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LWEParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LWEParams) ProtoMessage() {}
+
+func (x *LWEParams) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LWEParams.ProtoReflect.Descriptor instead.
+func (*LWEParams) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *LWEParams) GetN() uint32 {
+	if x != nil {
+		return x.N
+	}
+	return 0
+}
+
+func (x *LWEParams) GetNumRecords() uint32 {
+	if x != nil {
+		return x.NumRecords
+	}
+	return 0
+}
+
+func (x *LWEParams) GetRowLen() uint32 {
+	if x != nil {
+		return x.RowLen
+	}
+	return 0
+}
+
+func (x *LWEParams) GetNumCols() uint32 {
+	if x != nil {
+		return x.NumCols
+	}
+	return 0
+}
+
+func (x *LWEParams) GetM() uint32 {
+	if x != nil {
+		return x.M
+	}
+	return 0
+}
+
+func (x *LWEParams) GetSeed() []byte {
+	if x != nil {
+		return x.Seed
+	}
+	return nil
+}
+
+type LWEHint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Params *LWEParams `protobuf:"bytes,1,opt,name=params,proto3" json:"params,omitempty"`
+	Hint   []byte     `protobuf:"bytes,2,opt,name=hint,proto3" json:"hint,omitempty"` //flat list of uint32, little-endian
+}
+
+func (x *LWEHint) Reset() {
+	*x = LWEHint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bootstrapping_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LWEHint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LWEHint) ProtoMessage() {}
+
+func (x *LWEHint) ProtoReflect() protoreflect.Message {
+	mi := &file_bootstrapping_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LWEHint.ProtoReflect.Descriptor instead.
+func (*LWEHint) Descriptor() ([]byte, []int) {
+	return file_bootstrapping_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *LWEHint) GetParams() *LWEParams {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *LWEHint) GetHint() []byte {
+	if x != nil {
+		return x.Hint
+	}
+	return nil
+}
+
+var File_bootstrapping_proto protoreflect.FileDescriptor
+
+var file_bootstrapping_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x22, 0xda, 0x03, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
+	0x20, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x62, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x64, 0x62, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x75, 0x6c,
+	0x74, 0x69, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b,
+	0x6d, 0x75, 0x6c, 0x74, 0x69, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x6e,
+	0x75, 0x6d, 0x54, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x63,
+	0x49, 0x64, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x49, 0x64, 0x78, 0x12,
+	0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x64,
+	0x62, 0x54, 0x79, 0x70, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x62, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x53, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x73, 0x69, 0x6e, 0x67, 0x6c,
+	0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x79, 0x6c, 0x6f,
+	0x61, 0x64, 0x4c, 0x65, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x65, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x65, 0x70, 0x6f, 0x63, 0x68,
+	0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x65, 0x70,
+	0x6f, 0x63, 0x68, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x70, 0x6f,
+	0x63, 0x68, 0x53, 0x74, 0x61, 0x72, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x65,
+	0x70, 0x6f, 0x63, 0x68, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x65, 0x70, 0x6f,
+	0x63, 0x68, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0e, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x76, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43, 0x6f, 0x6c, 0x75, 0x6d,
+	0x6e, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x76, 0x65, 0x72, 0x69, 0x66, 0x79,
+	0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x68, 0x61,
+	0x72, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x73, 0x68, 0x61, 0x72,
+	0x65, 0x22, 0x42, 0x0a, 0x0c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x49, 0x64, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x63, 0x49, 0x64, 0x78, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x73, 0x22, 0x66, 0x0a, 0x09, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x4b, 0x57, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x03, 0x63, 0x4b, 0x57, 0x12, 0x2d, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x06, 0x70, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x22, 0xce, 0x02,
+	0x0a, 0x06, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x72, 0x6f, 0x77,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6e, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x61, 0x75,
+	0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x67, 0x4c, 0x65, 0x6e,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x73, 0x65, 0x67, 0x4c, 0x65, 0x6e, 0x12, 0x1e,
+	0x0a, 0x0a, 0x73, 0x65, 0x67, 0x4c, 0x65, 0x6e, 0x4d, 0x61, 0x73, 0x6b, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0a, 0x73, 0x65, 0x67, 0x4c, 0x65, 0x6e, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x1a,
+	0x0a, 0x08, 0x73, 0x65, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x73, 0x65, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x65,
+	0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0b, 0x73, 0x65, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06,
+	0x6b, 0x65, 0x79, 0x4c, 0x65, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6b, 0x65,
+	0x79, 0x4c, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x4c, 0x65, 0x6e, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x4c, 0x65, 0x6e, 0x12, 0x1c, 0x0a, 0x09,
+	0x72, 0x65, 0x63, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x09, 0x72, 0x65, 0x63, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f,
+	0x6f, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x4c, 0x65, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x4c, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69,
+	0x73, 0x74, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x22, 0x6c,
+	0x0a, 0x05, 0x53, 0x65, 0x74, 0x75, 0x70, 0x12, 0x33, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74,
+	0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x6f, 0x77, 0x6e, 0x4b, 0x57, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6f, 0x77, 0x6e,
+	0x4b, 0x57, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x22, 0x3b, 0x0a, 0x05,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x70, 0x66, 0x4b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x70, 0x66, 0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a,
+	0x08, 0x6c, 0x77, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x08, 0x6c, 0x77, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x22, 0x39, 0x0a, 0x07, 0x51, 0x75, 0x65,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x07, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61,
+	0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x07, 0x71, 0x75, 0x65,
+	0x72, 0x69, 0x65, 0x73, 0x22, 0x20, 0x0a, 0x06, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x12, 0x16,
+	0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
+	0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x22, 0x3a, 0x0a, 0x07, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72,
+	0x73, 0x12, 0x2f, 0x0a, 0x07, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69,
+	0x6e, 0x67, 0x2e, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x52, 0x07, 0x61, 0x6e, 0x73, 0x77, 0x65,
+	0x72, 0x73, 0x22, 0x94, 0x02, 0x0a, 0x0d, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x03, 0x69, 0x64, 0x78, 0x12, 0x27, 0x0a, 0x03, 0x76, 0x65, 0x63, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70,
+	0x69, 0x6e, 0x67, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x03, 0x76, 0x65, 0x63, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x65, 0x70, 0x6f, 0x63, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0c, 0x52, 0x05, 0x70, 0x61, 0x72, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x74,
+	0x72, 0x69, 0x70, 0x6c, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x72,
+	0x69, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x72, 0x69, 0x74, 0x65, 0x49, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x77, 0x72, 0x69, 0x74, 0x65, 0x49, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x04, 0x6b,
+	0x65, 0x79, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x4b, 0x65,
+	0x79, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0b, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x63, 0x53, 0x65, 0x65, 0x64,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x61, 0x63, 0x53, 0x65, 0x65, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x6d, 0x61, 0x63, 0x4b, 0x65, 0x79, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x07, 0x6d, 0x61, 0x63, 0x4b, 0x65, 0x79, 0x73, 0x22, 0x19, 0x0a, 0x05, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x03, 0x69, 0x64, 0x78, 0x22, 0x42, 0x0a, 0x0a, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x03, 0x69, 0x64, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x82, 0x01, 0x0a, 0x09, 0x45, 0x70, 0x6f,
+	0x63, 0x68, 0x52, 0x6f, 0x77, 0x73, 0x12, 0x29, 0x0a, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x04, 0x72, 0x6f, 0x77,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x22, 0x34, 0x0a,
+	0x0c, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03,
+	0x6d, 0x61, 0x63, 0x22, 0xa1, 0x01, 0x0a, 0x0b, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0d, 0x52, 0x06, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x72,
+	0x65, 0x73, 0x68, 0x61, 0x72, 0x65, 0x54, 0x61, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0a, 0x72, 0x65, 0x73, 0x68, 0x61, 0x72, 0x65, 0x54, 0x61, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6d, 0x61,
+	0x63, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x22, 0x96, 0x01, 0x0a, 0x0c, 0x44, 0x75, 0x72, 0x61,
+	0x62, 0x6c, 0x65, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x73, 0x68, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x70, 0x75, 0x62,
+	0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74,
+	0x12, 0x2e, 0x0a, 0x04, 0x6c, 0x61, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x57, 0x72, 0x69, 0x74, 0x65, 0x73, 0x52, 0x04, 0x6c, 0x61, 0x73, 0x74,
+	0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x74,
+	0x22, 0xad, 0x01, 0x0a, 0x0d, 0x53, 0x6b, 0x65, 0x74, 0x63, 0x68, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x72, 0x69, 0x74, 0x65, 0x49, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x77, 0x72, 0x69, 0x74, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x12, 0x16,
+	0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x04, 0x52, 0x06,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x03, 0x6d, 0x61, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x78, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x69, 0x64, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70,
+	0x6f, 0x63, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68,
+	0x22, 0x59, 0x0a, 0x13, 0x52, 0x65, 0x73, 0x68, 0x61, 0x72, 0x65, 0x4e, 0x6f, 0x6e, 0x63, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x12, 0x1a, 0x0a,
+	0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6d, 0x61, 0x63, 0x22, 0x68, 0x0a, 0x0c, 0x52,
+	0x65, 0x73, 0x68, 0x61, 0x72, 0x65, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x70, 0x6f, 0x63, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63,
+	0x68, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x44, 0x12, 0x14, 0x0a,
+	0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6e, 0x6f,
+	0x6e, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x03, 0x6d, 0x61, 0x63, 0x22, 0x5c, 0x0a, 0x06, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12,
+	0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x76, 0x61,
+	0x6c, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x70, 0x6f, 0x63, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63,
+	0x68, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03,
+	0x6d, 0x61, 0x63, 0x22, 0x2b, 0x0a, 0x03, 0x41, 0x63, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70,
+	0x6f, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68,
+	0x22, 0x4f, 0x0a, 0x0b, 0x48, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x66, 0x72, 0x6f,
+	0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x74,
+	0x6f, 0x22, 0x8d, 0x01, 0x0a, 0x09, 0x4c, 0x57, 0x45, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12,
+	0x0c, 0x0a, 0x01, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x01, 0x6e, 0x12, 0x1e, 0x0a,
+	0x0a, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x6f, 0x77, 0x4c, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x72,
+	0x6f, 0x77, 0x4c, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x75, 0x6d, 0x43, 0x6f, 0x6c, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6e, 0x75, 0x6d, 0x43, 0x6f, 0x6c, 0x73, 0x12,
+	0x0c, 0x0a, 0x01, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x01, 0x6d, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x73, 0x65, 0x65,
+	0x64, 0x22, 0x4f, 0x0a, 0x07, 0x4c, 0x57, 0x45, 0x48, 0x69, 0x6e, 0x74, 0x12, 0x30, 0x0a, 0x06,
+	0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x62,
+	0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x4c, 0x57, 0x45,
+	0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x68, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x68, 0x69,
+	0x6e, 0x74, 0x32, 0xa2, 0x08, 0x0a, 0x0d, 0x42, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x12, 0x44, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x75, 0x70, 0x45, 0x78, 0x70,
+	0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74,
+	0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x1a, 0x18,
+	0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50,
+	0x61, 0x72, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0d, 0x47, 0x65,
+	0x74, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x1b, 0x2e, 0x62, 0x6f,
+	0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50, 0x61, 0x72, 0x61,
+	0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73,
+	0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22,
+	0x00, 0x12, 0x3f, 0x0a, 0x09, 0x53, 0x65, 0x74, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1c,
+	0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x4e,
+	0x6f, 0x74, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x62,
+	0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x63, 0x6b,
+	0x22, 0x00, 0x12, 0x37, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x77, 0x12, 0x14, 0x2e, 0x62,
+	0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x1a, 0x15, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69,
+	0x6e, 0x67, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x07, 0x47,
+	0x65, 0x74, 0x52, 0x6f, 0x77, 0x73, 0x12, 0x19, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72,
+	0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x1a, 0x18, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e,
+	0x67, 0x2e, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x52, 0x6f, 0x77, 0x73, 0x22, 0x00, 0x12, 0x47, 0x0a,
+	0x0a, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1b, 0x2e, 0x62, 0x6f,
+	0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x6f, 0x75, 0x6e,
+	0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73,
+	0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x57, 0x72,
+	0x69, 0x74, 0x65, 0x73, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x0c, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73,
+	0x68, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1a, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72,
+	0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x57, 0x72, 0x69, 0x74,
+	0x65, 0x73, 0x1a, 0x12, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69,
+	0x6e, 0x67, 0x2e, 0x41, 0x63, 0x6b, 0x22, 0x00, 0x12, 0x4d, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x44,
+	0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1b, 0x2e, 0x62, 0x6f,
+	0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x6f, 0x75, 0x6e,
+	0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73,
+	0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65,
+	0x52, 0x6f, 0x75, 0x6e, 0x64, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0e, 0x45, 0x78, 0x63, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x53, 0x6b, 0x65, 0x74, 0x63, 0x68, 0x12, 0x1c, 0x2e, 0x62, 0x6f, 0x6f, 0x74,
+	0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x53, 0x6b, 0x65, 0x74, 0x63, 0x68,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x12, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74,
+	0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x63, 0x6b, 0x22, 0x00, 0x12, 0x54, 0x0a,
+	0x0f, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x68, 0x61, 0x72, 0x65, 0x4e, 0x6f, 0x6e, 0x63, 0x65,
+	0x12, 0x22, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67,
+	0x2e, 0x52, 0x65, 0x73, 0x68, 0x61, 0x72, 0x65, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70,
+	0x70, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x73, 0x68, 0x61, 0x72, 0x65, 0x4e, 0x6f, 0x6e, 0x63,
+	0x65, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x0c, 0x4d, 0x61, 0x6b, 0x65, 0x49, 0x51, 0x75, 0x65, 0x72,
+	0x69, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70,
+	0x69, 0x6e, 0x67, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x1a, 0x16, 0x2e, 0x62, 0x6f,
+	0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x6e, 0x73, 0x77,
+	0x65, 0x72, 0x73, 0x22, 0x00, 0x12, 0x41, 0x0a, 0x0d, 0x4d, 0x61, 0x6b, 0x65, 0x4b, 0x57, 0x51,
+	0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72,
+	0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x1a, 0x16,
+	0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41,
+	0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x22, 0x00, 0x12, 0x42, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x4c,
+	0x57, 0x45, 0x48, 0x69, 0x6e, 0x74, 0x12, 0x1a, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72,
+	0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x48, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69,
+	0x6e, 0x67, 0x2e, 0x4c, 0x57, 0x45, 0x48, 0x69, 0x6e, 0x74, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x0f,
+	0x4d, 0x61, 0x6b, 0x65, 0x4c, 0x57, 0x45, 0x49, 0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12,
+	0x16, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e,
+	0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x1a, 0x16, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74,
+	0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x22,
+	0x00, 0x12, 0x44, 0x0a, 0x10, 0x4d, 0x61, 0x6b, 0x65, 0x4c, 0x57, 0x45, 0x4b, 0x57, 0x51, 0x75,
+	0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61,
+	0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x1a, 0x16, 0x2e,
+	0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x2e, 0x41, 0x6e,
+	0x73, 0x77, 0x65, 0x72, 0x73, 0x22, 0x00, 0x42, 0x15, 0x5a, 0x13, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_bootstrapping_proto_rawDescOnce sync.Once
+	file_bootstrapping_proto_rawDescData = file_bootstrapping_proto_rawDesc
+)
+
+func file_bootstrapping_proto_rawDescGZIP() []byte {
+	file_bootstrapping_proto_rawDescOnce.Do(func() {
+		file_bootstrapping_proto_rawDescData = protoimpl.X.CompressGZIP(file_bootstrapping_proto_rawDescData)
+	})
+	return file_bootstrapping_proto_rawDescData
+}
+
+var file_bootstrapping_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_bootstrapping_proto_goTypes = []interface{}{
+	(*Config)(nil),              // 0: bootstrapping.Config
+	(*ParamRequest)(nil),        // 1: bootstrapping.ParamRequest
+	(*ParamResp)(nil),           // 2: bootstrapping.ParamResp
+	(*Params)(nil),              // 3: bootstrapping.Params
+	(*Setup)(nil),               // 4: bootstrapping.Setup
+	(*Query)(nil),               // 5: bootstrapping.Query
+	(*Queries)(nil),             // 6: bootstrapping.Queries
+	(*Answer)(nil),              // 7: bootstrapping.Answer
+	(*Answers)(nil),             // 8: bootstrapping.Answers
+	(*NotifyRequest)(nil),       // 9: bootstrapping.NotifyRequest
+	(*Index)(nil),               // 10: bootstrapping.Index
+	(*EpochIndex)(nil),          // 11: bootstrapping.EpochIndex
+	(*EpochRows)(nil),           // 12: bootstrapping.EpochRows
+	(*RoundRequest)(nil),        // 13: bootstrapping.RoundRequest
+	(*RoundWrites)(nil),         // 14: bootstrapping.RoundWrites
+	(*DurableRound)(nil),        // 15: bootstrapping.DurableRound
+	(*SketchMessage)(nil),       // 16: bootstrapping.SketchMessage
+	(*ReshareNonceRequest)(nil), // 17: bootstrapping.ReshareNonceRequest
+	(*ReshareNonce)(nil),        // 18: bootstrapping.ReshareNonce
+	(*Vector)(nil),              // 19: bootstrapping.Vector
+	(*Ack)(nil),                 // 20: bootstrapping.Ack
+	(*HintRequest)(nil),         // 21: bootstrapping.HintRequest
+	(*LWEParams)(nil),           // 22: bootstrapping.LWEParams
+	(*LWEHint)(nil),             // 23: bootstrapping.LWEHint
+}
+var file_bootstrapping_proto_depIdxs = []int32{
+	3,  // 0: bootstrapping.ParamResp.params:type_name -> bootstrapping.Params
+	1,  // 1: bootstrapping.Setup.params:type_name -> bootstrapping.ParamRequest
+	5,  // 2: bootstrapping.Queries.queries:type_name -> bootstrapping.Query
+	7,  // 3: bootstrapping.Answers.answers:type_name -> bootstrapping.Answer
+	19, // 4: bootstrapping.NotifyRequest.vec:type_name -> bootstrapping.Vector
+	19, // 5: bootstrapping.EpochRows.rows:type_name -> bootstrapping.Vector
+	14, // 6: bootstrapping.DurableRound.last:type_name -> bootstrapping.RoundWrites
+	22, // 7: bootstrapping.LWEHint.params:type_name -> bootstrapping.LWEParams
+	0,  // 8: bootstrapping.Bootstrapping.SetupExperiment:input_type -> bootstrapping.Config
+	1,  // 9: bootstrapping.Bootstrapping.GetParameters:input_type -> bootstrapping.ParamRequest
+	9,  // 10: bootstrapping.Bootstrapping.SetColumn:input_type -> bootstrapping.NotifyRequest
+	10, // 11: bootstrapping.Bootstrapping.GetRow:input_type -> bootstrapping.Index
+	11, // 12: bootstrapping.Bootstrapping.GetRows:input_type -> bootstrapping.EpochIndex
+	13, // 13: bootstrapping.Bootstrapping.CloseRound:input_type -> bootstrapping.RoundRequest
+	14, // 14: bootstrapping.Bootstrapping.PublishRound:input_type -> bootstrapping.RoundWrites
+	13, // 15: bootstrapping.Bootstrapping.GetDurableRound:input_type -> bootstrapping.RoundRequest
+	16, // 16: bootstrapping.Bootstrapping.ExchangeSketch:input_type -> bootstrapping.SketchMessage
+	17, // 17: bootstrapping.Bootstrapping.GetReshareNonce:input_type -> bootstrapping.ReshareNonceRequest
+	6,  // 18: bootstrapping.Bootstrapping.MakeIQueries:input_type -> bootstrapping.Queries
+	6,  // 19: bootstrapping.Bootstrapping.MakeKWQueries:input_type -> bootstrapping.Queries
+	21, // 20: bootstrapping.Bootstrapping.GetLWEHint:input_type -> bootstrapping.HintRequest
+	6,  // 21: bootstrapping.Bootstrapping.MakeLWEIQueries:input_type -> bootstrapping.Queries
+	6,  // 22: bootstrapping.Bootstrapping.MakeLWEKWQueries:input_type -> bootstrapping.Queries
+	2,  // 23: bootstrapping.Bootstrapping.SetupExperiment:output_type -> bootstrapping.ParamResp
+	3,  // 24: bootstrapping.Bootstrapping.GetParameters:output_type -> bootstrapping.Params
+	20, // 25: bootstrapping.Bootstrapping.SetColumn:output_type -> bootstrapping.Ack
+	19, // 26: bootstrapping.Bootstrapping.GetRow:output_type -> bootstrapping.Vector
+	12, // 27: bootstrapping.Bootstrapping.GetRows:output_type -> bootstrapping.EpochRows
+	14, // 28: bootstrapping.Bootstrapping.CloseRound:output_type -> bootstrapping.RoundWrites
+	20, // 29: bootstrapping.Bootstrapping.PublishRound:output_type -> bootstrapping.Ack
+	15, // 30: bootstrapping.Bootstrapping.GetDurableRound:output_type -> bootstrapping.DurableRound
+	20, // 31: bootstrapping.Bootstrapping.ExchangeSketch:output_type -> bootstrapping.Ack
+	18, // 32: bootstrapping.Bootstrapping.GetReshareNonce:output_type -> bootstrapping.ReshareNonce
+	8,  // 33: bootstrapping.Bootstrapping.MakeIQueries:output_type -> bootstrapping.Answers
+	8,  // 34: bootstrapping.Bootstrapping.MakeKWQueries:output_type -> bootstrapping.Answers
+	23, // 35: bootstrapping.Bootstrapping.GetLWEHint:output_type -> bootstrapping.LWEHint
+	8,  // 36: bootstrapping.Bootstrapping.MakeLWEIQueries:output_type -> bootstrapping.Answers
+	8,  // 37: bootstrapping.Bootstrapping.MakeLWEKWQueries:output_type -> bootstrapping.Answers
+	23, // [23:38] is the sub-list for method output_type
+	8,  // [8:23] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_bootstrapping_proto_init() }
+func file_bootstrapping_proto_init() {
+	if File_bootstrapping_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_bootstrapping_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Config); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ParamResp); i {
 			case 0:
 				return &v.state
@@ -1134,7 +2261,7 @@ func file_bootstrapping_proto_init() {
 			}
 		}
 		file_bootstrapping_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Vector); i {
+			switch v := v.(*EpochIndex); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1146,6 +2273,102 @@ func file_bootstrapping_proto_init() {
 			}
 		}
 		file_bootstrapping_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EpochRows); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoundRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoundWrites); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DurableRound); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SketchMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReshareNonceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReshareNonce); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Vector); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Ack); i {
 			case 0:
 				return &v.state
@@ -1157,6 +2380,42 @@ func file_bootstrapping_proto_init() {
 				return nil
 			}
 		}
+		file_bootstrapping_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HintRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LWEParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bootstrapping_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LWEHint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1164,7 +2423,7 @@ func file_bootstrapping_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_bootstrapping_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -26,8 +26,17 @@ type BootstrappingClient interface {
 	GetParameters(ctx context.Context, in *ParamRequest, opts ...grpc.CallOption) (*Params, error)
 	SetColumn(ctx context.Context, in *NotifyRequest, opts ...grpc.CallOption) (*Ack, error)
 	GetRow(ctx context.Context, in *Index, opts ...grpc.CallOption) (*Vector, error)
+	GetRows(ctx context.Context, in *EpochIndex, opts ...grpc.CallOption) (*EpochRows, error)
+	CloseRound(ctx context.Context, in *RoundRequest, opts ...grpc.CallOption) (*RoundWrites, error)
+	PublishRound(ctx context.Context, in *RoundWrites, opts ...grpc.CallOption) (*Ack, error)
+	GetDurableRound(ctx context.Context, in *RoundRequest, opts ...grpc.CallOption) (*DurableRound, error)
+	ExchangeSketch(ctx context.Context, in *SketchMessage, opts ...grpc.CallOption) (*Ack, error)
+	GetReshareNonce(ctx context.Context, in *ReshareNonceRequest, opts ...grpc.CallOption) (*ReshareNonce, error)
 	MakeIQueries(ctx context.Context, in *Queries, opts ...grpc.CallOption) (*Answers, error)
 	MakeKWQueries(ctx context.Context, in *Queries, opts ...grpc.CallOption) (*Answers, error)
+	GetLWEHint(ctx context.Context, in *HintRequest, opts ...grpc.CallOption) (*LWEHint, error)
+	MakeLWEIQueries(ctx context.Context, in *Queries, opts ...grpc.CallOption) (*Answers, error)
+	MakeLWEKWQueries(ctx context.Context, in *Queries, opts ...grpc.CallOption) (*Answers, error)
 }
 
 type bootstrappingClient struct {
@@ -74,6 +83,60 @@ func (c *bootstrappingClient) GetRow(ctx context.Context, in *Index, opts ...grp
 	return out, nil
 }
 
+func (c *bootstrappingClient) GetRows(ctx context.Context, in *EpochIndex, opts ...grpc.CallOption) (*EpochRows, error) {
+	out := new(EpochRows)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/GetRows", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootstrappingClient) CloseRound(ctx context.Context, in *RoundRequest, opts ...grpc.CallOption) (*RoundWrites, error) {
+	out := new(RoundWrites)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/CloseRound", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootstrappingClient) PublishRound(ctx context.Context, in *RoundWrites, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/PublishRound", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootstrappingClient) GetDurableRound(ctx context.Context, in *RoundRequest, opts ...grpc.CallOption) (*DurableRound, error) {
+	out := new(DurableRound)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/GetDurableRound", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootstrappingClient) ExchangeSketch(ctx context.Context, in *SketchMessage, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/ExchangeSketch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootstrappingClient) GetReshareNonce(ctx context.Context, in *ReshareNonceRequest, opts ...grpc.CallOption) (*ReshareNonce, error) {
+	out := new(ReshareNonce)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/GetReshareNonce", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *bootstrappingClient) MakeIQueries(ctx context.Context, in *Queries, opts ...grpc.CallOption) (*Answers, error) {
 	out := new(Answers)
 	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/MakeIQueries", in, out, opts...)
@@ -92,6 +155,33 @@ func (c *bootstrappingClient) MakeKWQueries(ctx context.Context, in *Queries, op
 	return out, nil
 }
 
+func (c *bootstrappingClient) GetLWEHint(ctx context.Context, in *HintRequest, opts ...grpc.CallOption) (*LWEHint, error) {
+	out := new(LWEHint)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/GetLWEHint", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootstrappingClient) MakeLWEIQueries(ctx context.Context, in *Queries, opts ...grpc.CallOption) (*Answers, error) {
+	out := new(Answers)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/MakeLWEIQueries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bootstrappingClient) MakeLWEKWQueries(ctx context.Context, in *Queries, opts ...grpc.CallOption) (*Answers, error) {
+	out := new(Answers)
+	err := c.cc.Invoke(ctx, "/bootstrapping.Bootstrapping/MakeLWEKWQueries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BootstrappingServer is the server API for Bootstrapping service.
 // All implementations must embed UnimplementedBootstrappingServer
 // for forward compatibility
@@ -100,8 +190,17 @@ type BootstrappingServer interface {
 	GetParameters(context.Context, *ParamRequest) (*Params, error)
 	SetColumn(context.Context, *NotifyRequest) (*Ack, error)
 	GetRow(context.Context, *Index) (*Vector, error)
+	GetRows(context.Context, *EpochIndex) (*EpochRows, error)
+	CloseRound(context.Context, *RoundRequest) (*RoundWrites, error)
+	PublishRound(context.Context, *RoundWrites) (*Ack, error)
+	GetDurableRound(context.Context, *RoundRequest) (*DurableRound, error)
+	ExchangeSketch(context.Context, *SketchMessage) (*Ack, error)
+	GetReshareNonce(context.Context, *ReshareNonceRequest) (*ReshareNonce, error)
 	MakeIQueries(context.Context, *Queries) (*Answers, error)
 	MakeKWQueries(context.Context, *Queries) (*Answers, error)
+	GetLWEHint(context.Context, *HintRequest) (*LWEHint, error)
+	MakeLWEIQueries(context.Context, *Queries) (*Answers, error)
+	MakeLWEKWQueries(context.Context, *Queries) (*Answers, error)
 	mustEmbedUnimplementedBootstrappingServer()
 }
 
@@ -121,12 +220,39 @@ func (UnimplementedBootstrappingServer) SetColumn(context.Context, *NotifyReques
 func (UnimplementedBootstrappingServer) GetRow(context.Context, *Index) (*Vector, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRow not implemented")
 }
+func (UnimplementedBootstrappingServer) GetRows(context.Context, *EpochIndex) (*EpochRows, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRows not implemented")
+}
+func (UnimplementedBootstrappingServer) CloseRound(context.Context, *RoundRequest) (*RoundWrites, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseRound not implemented")
+}
+func (UnimplementedBootstrappingServer) PublishRound(context.Context, *RoundWrites) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishRound not implemented")
+}
+func (UnimplementedBootstrappingServer) GetDurableRound(context.Context, *RoundRequest) (*DurableRound, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDurableRound not implemented")
+}
+func (UnimplementedBootstrappingServer) ExchangeSketch(context.Context, *SketchMessage) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExchangeSketch not implemented")
+}
+func (UnimplementedBootstrappingServer) GetReshareNonce(context.Context, *ReshareNonceRequest) (*ReshareNonce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReshareNonce not implemented")
+}
 func (UnimplementedBootstrappingServer) MakeIQueries(context.Context, *Queries) (*Answers, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MakeIQueries not implemented")
 }
 func (UnimplementedBootstrappingServer) MakeKWQueries(context.Context, *Queries) (*Answers, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MakeKWQueries not implemented")
 }
+func (UnimplementedBootstrappingServer) GetLWEHint(context.Context, *HintRequest) (*LWEHint, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLWEHint not implemented")
+}
+func (UnimplementedBootstrappingServer) MakeLWEIQueries(context.Context, *Queries) (*Answers, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MakeLWEIQueries not implemented")
+}
+func (UnimplementedBootstrappingServer) MakeLWEKWQueries(context.Context, *Queries) (*Answers, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MakeLWEKWQueries not implemented")
+}
 func (UnimplementedBootstrappingServer) mustEmbedUnimplementedBootstrappingServer() {}
 
 // UnsafeBootstrappingServer may be embedded to opt out of forward compatibility for this service.
@@ -212,6 +338,114 @@ func _Bootstrapping_GetRow_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Bootstrapping_GetRows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EpochIndex)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).GetRows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/GetRows",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).GetRows(ctx, req.(*EpochIndex))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bootstrapping_CloseRound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).CloseRound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/CloseRound",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).CloseRound(ctx, req.(*RoundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bootstrapping_PublishRound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoundWrites)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).PublishRound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/PublishRound",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).PublishRound(ctx, req.(*RoundWrites))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bootstrapping_GetDurableRound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).GetDurableRound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/GetDurableRound",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).GetDurableRound(ctx, req.(*RoundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bootstrapping_ExchangeSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SketchMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).ExchangeSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/ExchangeSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).ExchangeSketch(ctx, req.(*SketchMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bootstrapping_GetReshareNonce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReshareNonceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).GetReshareNonce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/GetReshareNonce",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).GetReshareNonce(ctx, req.(*ReshareNonceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Bootstrapping_MakeIQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Queries)
 	if err := dec(in); err != nil {
@@ -248,6 +482,60 @@ func _Bootstrapping_MakeKWQueries_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Bootstrapping_GetLWEHint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HintRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).GetLWEHint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/GetLWEHint",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).GetLWEHint(ctx, req.(*HintRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bootstrapping_MakeLWEIQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Queries)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).MakeLWEIQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/MakeLWEIQueries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).MakeLWEIQueries(ctx, req.(*Queries))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bootstrapping_MakeLWEKWQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Queries)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BootstrappingServer).MakeLWEKWQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bootstrapping.Bootstrapping/MakeLWEKWQueries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BootstrappingServer).MakeLWEKWQueries(ctx, req.(*Queries))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Bootstrapping_ServiceDesc is the grpc.ServiceDesc for Bootstrapping service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -271,6 +559,30 @@ var Bootstrapping_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetRow",
 			Handler:    _Bootstrapping_GetRow_Handler,
 		},
+		{
+			MethodName: "GetRows",
+			Handler:    _Bootstrapping_GetRows_Handler,
+		},
+		{
+			MethodName: "CloseRound",
+			Handler:    _Bootstrapping_CloseRound_Handler,
+		},
+		{
+			MethodName: "PublishRound",
+			Handler:    _Bootstrapping_PublishRound_Handler,
+		},
+		{
+			MethodName: "GetDurableRound",
+			Handler:    _Bootstrapping_GetDurableRound_Handler,
+		},
+		{
+			MethodName: "ExchangeSketch",
+			Handler:    _Bootstrapping_ExchangeSketch_Handler,
+		},
+		{
+			MethodName: "GetReshareNonce",
+			Handler:    _Bootstrapping_GetReshareNonce_Handler,
+		},
 		{
 			MethodName: "MakeIQueries",
 			Handler:    _Bootstrapping_MakeIQueries_Handler,
@@ -279,6 +591,18 @@ var Bootstrapping_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "MakeKWQueries",
 			Handler:    _Bootstrapping_MakeKWQueries_Handler,
 		},
+		{
+			MethodName: "GetLWEHint",
+			Handler:    _Bootstrapping_GetLWEHint_Handler,
+		},
+		{
+			MethodName: "MakeLWEIQueries",
+			Handler:    _Bootstrapping_MakeLWEIQueries_Handler,
+		},
+		{
+			MethodName: "MakeLWEKWQueries",
+			Handler:    _Bootstrapping_MakeLWEKWQueries_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "bootstrapping.proto",